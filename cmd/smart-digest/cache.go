@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/taro33333/smart-digest/internal/cache"
+	"github.com/taro33333/smart-digest/internal/config"
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect and manage the article/analysis cache",
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show cache entry counts and sizes",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadCache()
+		if err != nil {
+			return err
+		}
+
+		stats, err := store.Stats()
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		fmt.Fprintf(out, "Cache directory: %s\n\n", store.Dir())
+		fmt.Fprintf(out, "%-10s %8s %8s %10s\n", "KIND", "ENTRIES", "EXPIRED", "SIZE")
+		fmt.Fprintf(out, "%-10s %8d %8d %10s\n", "articles", stats.Articles.Entries, stats.Articles.Expired, formatBytes(stats.Articles.Bytes))
+		fmt.Fprintf(out, "%-10s %8d %8d %10s\n", "analyses", stats.Analyses.Entries, stats.Analyses.Expired, formatBytes(stats.Analyses.Bytes))
+		fmt.Fprintf(out, "%-10s %8d %8d %10s\n", "pages", stats.Pages.Entries, stats.Pages.Expired, formatBytes(stats.Pages.Bytes))
+		return nil
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove expired cache entries",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadCache()
+		if err != nil {
+			return err
+		}
+
+		removed, err := store.Prune()
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Removed %d expired entries\n", removed)
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cache entries",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		store, err := loadCache()
+		if err != nil {
+			return err
+		}
+
+		if err := store.Clear(); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "Cleared %s\n", store.Dir())
+		return nil
+	},
+}
+
+func init() {
+	cacheCmd.AddCommand(cacheStatsCmd, cachePruneCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
+
+// loadCache opens the cache configured in the config file. The rest of the
+// config is not validated, so the cache can be managed without API keys.
+func loadCache() (*cache.Cache, error) {
+	cfg, err := config.Read(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("configuration error: %w", err)
+	}
+	return openCache(cfg)
+}
+
+// openCache opens the cache directory from cfg, defaulting to the XDG cache dir.
+func openCache(cfg *config.Config) (*cache.Cache, error) {
+	dir := cfg.Cache.Dir
+	if dir == "" {
+		var err error
+		if dir, err = cache.DefaultDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	store, err := cache.Open(dir, cfg.Cache.ArticleTTL, cfg.Cache.AnalysisTTL)
+	if err != nil {
+		return nil, fmt.Errorf("cache error: %w", err)
+	}
+	return store, nil
+}
+
+// formatBytes renders a byte count in human-readable units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
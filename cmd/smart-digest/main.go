@@ -7,14 +7,16 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"text/template"
+	"time"
 
 	"github.com/schollz/progressbar/v3"
 	"github.com/spf13/cobra"
 
 	"github.com/taro33333/smart-digest/internal/config"
-	"github.com/taro33333/smart-digest/internal/fetcher"
 	"github.com/taro33333/smart-digest/internal/input"
-	"github.com/taro33333/smart-digest/internal/llm"
+	"github.com/taro33333/smart-digest/internal/journal"
+	"github.com/taro33333/smart-digest/internal/notify"
 	"github.com/taro33333/smart-digest/internal/output"
 	"github.com/taro33333/smart-digest/internal/processor"
 )
@@ -29,6 +31,15 @@ var (
 	thresholdFlag  int
 	verboseFlag    bool
 	maxWorkersFlag int
+	noCacheFlag    bool
+	refreshFlag    bool
+	feedFlags      []string
+	sinceFlag      string
+	onlyNewFlag    bool
+	templateFlag   string
+	groupByFlag    string
+	noNotifyFlag   bool
+	resumeFlag     string
 )
 
 func main() {
@@ -53,34 +64,44 @@ Examples:
   echo '{"url":"https://example.com"}' | smart-digest
 
   # Integration with update-watcher
-  update-watcher | smart-digest`,
+  update-watcher | smart-digest
+
+  # Entries from the last two days of a feed
+  smart-digest --feed "https://go.dev/blog/feed.atom" --since 48h
+
+  # Continue an interrupted run (requires journal.enabled)
+  smart-digest --resume 20240115-093000-1a2b
+
+By default nothing is written besides the report. Enabling cache.enabled,
+history.enabled or journal.enabled in the config stores fetched articles,
+analyses, seen articles and run progress under the XDG cache and state
+directories.`,
 	Version: version,
 	RunE:    run,
 }
 
 func init() {
 	rootCmd.Flags().StringVarP(&urlFlag, "url", "u", "", "URL to analyze")
-	rootCmd.Flags().StringVarP(&configPath, "config", "c", "", "Path to config file")
-	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "markdown", "Output format (markdown, json)")
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "", "Path to config file")
+	rootCmd.Flags().StringVarP(&outputFormat, "format", "f", "markdown", "Output format (markdown, json, jsonl, html)")
 	rootCmd.Flags().IntVarP(&thresholdFlag, "threshold", "t", -1, "Override score threshold (0-100)")
 	rootCmd.Flags().BoolVarP(&verboseFlag, "verbose", "v", false, "Verbose output")
 	rootCmd.Flags().IntVarP(&maxWorkersFlag, "workers", "w", -1, "Override max workers")
+	rootCmd.Flags().BoolVar(&noCacheFlag, "no-cache", false, "Do not read or write the cache")
+	rootCmd.Flags().BoolVar(&refreshFlag, "refresh", false, "Ignore cached entries but store fresh results")
+	rootCmd.Flags().StringArrayVar(&feedFlags, "feed", nil, "RSS/Atom/JSON Feed URL to read entries from (repeatable)")
+	rootCmd.Flags().StringVar(&sinceFlag, "since", "", "Only feed entries newer than this (e.g. 48h, 2024-01-15)")
+	rootCmd.Flags().StringVar(&templateFlag, "template", "", "Render the report with a Go text/template file instead of --format")
+	rootCmd.Flags().StringVar(&groupByFlag, "group-by", "", "Group the Markdown/HTML report into sections (category, project, interest, source)")
+	rootCmd.Flags().BoolVar(&noNotifyFlag, "no-notify", false, "Do not send the digest to the configured notification sinks")
+	rootCmd.Flags().BoolVar(&onlyNewFlag, "only-new", false, "Skip articles already reported in earlier runs unless their content changed (requires history.enabled)")
+	rootCmd.Flags().StringVar(&resumeFlag, "resume", "", "Resume an interrupted run by its run ID, skipping jobs it finished")
 }
 
 func run(cmd *cobra.Command, args []string) error {
-	// Setup context with cancellation
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := signalContext()
 	defer cancel()
 
-	// Handle signals gracefully
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	go func() {
-		<-sigChan
-		fmt.Fprintln(os.Stderr, "\n⚠️  Interrupted, shutting down...")
-		cancel()
-	}()
-
 	// Load configuration
 	cfg, err := config.Load(configPath)
 	if err != nil {
@@ -94,34 +115,115 @@ func run(cmd *cobra.Command, args []string) error {
 	if maxWorkersFlag > 0 {
 		cfg.MaxWorkers = maxWorkersFlag
 	}
-
-	// Collect jobs from input
-	jobs, err := collectJobs(args)
+	switch outputFormat {
+	case "markdown", "json", "jsonl", "html":
+	default:
+		return fmt.Errorf("invalid format: %s (must be 'markdown', 'json', 'jsonl' or 'html')", outputFormat)
+	}
+	groupBy, err := output.ParseGroupBy(groupByFlag)
 	if err != nil {
-		return fmt.Errorf("input error: %w", err)
+		return err
+	}
+	if templateFlag != "" && cmd.Flags().Changed("format") {
+		return fmt.Errorf("--template cannot be combined with --format")
+	}
+	if onlyNewFlag && !cfg.History.Enabled {
+		return fmt.Errorf("--only-new requires history.enabled in the config")
+	}
+	if resumeFlag != "" && (urlFlag != "" || len(feedFlags) > 0 || len(args) > 0) {
+		return fmt.Errorf("--resume reads the jobs from the run journal and cannot be combined with other input")
+	}
+
+	// Load the output template before spending time on processing
+	var tmpl *template.Template
+	if templateFlag != "" {
+		if tmpl, err = output.LoadTemplate(templateFlag); err != nil {
+			return err
+		}
+	}
+
+	var sinks []notify.Sink
+	if !noNotifyFlag {
+		if sinks, err = newSinks(cfg); err != nil {
+			return err
+		}
 	}
 
-	if len(jobs) == 0 {
-		return fmt.Errorf("no URLs provided. Use --url flag or pipe JSON to stdin")
+	// Collect jobs from input, or from the journal of the run to resume
+	var jobs []processor.Job
+	var restored []processor.Result
+	var jnl *journal.Journal
+	if resumeFlag != "" {
+		dir, err := journalDir(cfg)
+		if err != nil {
+			return err
+		}
+		if jnl, jobs, restored, err = journal.Resume(dir, resumeFlag); err != nil {
+			return fmt.Errorf("resume error: %w", err)
+		}
+		defer jnl.Close()
+		fmt.Fprintf(os.Stderr, "⏯️  Resuming run %s: %d done, %d remaining\n", jnl.ID(), len(restored), len(jobs))
+	} else {
+		if jobs, err = collectJobs(ctx, cfg, args); err != nil {
+			return fmt.Errorf("input error: %w", err)
+		}
+		if len(jobs) == 0 {
+			return fmt.Errorf("no URLs provided. Use --url or --feed, pipe JSON to stdin, or configure feeds")
+		}
 	}
 
 	if verboseFlag {
 		fmt.Fprintf(os.Stderr, "📋 Processing %d URLs...\n", len(jobs))
 		fmt.Fprintf(os.Stderr, "🤖 LLM: %s (%s)\n", cfg.LLMProvider, cfg.Model)
+		for _, fb := range cfg.Fallback {
+			fmt.Fprintf(os.Stderr, "   ↳ fallback: %s (%s)\n", fb.Provider, fb.Model)
+		}
 		fmt.Fprintf(os.Stderr, "🎯 Interests: %s\n", cfg.InterestsString())
+		adaptive := ""
+		if cfg.Adaptive.Enabled {
+			adaptive = " (adaptive)"
+		}
+		fmt.Fprintf(os.Stderr, "⚙️  LLM limits: %d concurrent, %.2f req/s%s\n", cfg.MaxWorkers, cfg.RateLimit, adaptive)
 		fmt.Fprintf(os.Stderr, "📊 Threshold: %d\n\n", cfg.Threshold)
 	}
 
 	// Initialize components
-	f := fetcher.New()
-
-	provider, err := llm.NewProvider(cfg)
+	opts := pipelineOptions{
+		noCache: noCacheFlag,
+		refresh: refreshFlag,
+		onlyNew: onlyNewFlag,
+	}
+	if verboseFlag {
+		opts.limitReporter = reportLimits
+	}
+	proc, seen, err := newProcessor(cfg, opts)
 	if err != nil {
-		return fmt.Errorf("LLM initialization error: %w", err)
+		return err
 	}
+	formatter := output.New(cfg.Threshold, formatOptions(cfg, output.WithGroupBy(groupBy))...)
 
-	proc := processor.New(f, provider, cfg.Interests, cfg.MaxWorkers, cfg.RateLimit)
-	formatter := output.New(cfg.Threshold)
+	if jnl == nil && cfg.Journal.Enabled {
+		dir, err := journalDir(cfg)
+		if err != nil {
+			return err
+		}
+		if jnl, err = journal.Create(dir, journal.NewID(time.Now()), jobs); err != nil {
+			return fmt.Errorf("journal error: %w", err)
+		}
+		defer jnl.Close()
+		if verboseFlag {
+			fmt.Fprintf(os.Stderr, "📝 Run ID: %s\n", jnl.ID())
+		}
+	}
+
+	// A resumed run streams the results it restored first
+	if outputFormat == "jsonl" {
+		for _, r := range restored {
+			if err := formatter.WriteJSONLine(os.Stdout, r); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+			}
+		}
+	}
 
 	// Create progress bar
 	var bar *progressbar.ProgressBar
@@ -152,12 +254,31 @@ func run(cmd *cobra.Command, args []string) error {
 			status := "✅"
 			if result.Error != nil {
 				status = "❌"
+			} else if result.Skipped {
+				status = "⏭️"
+			} else if result.Cached {
+				status = "♻️"
 			}
 			fmt.Fprintf(os.Stderr, "%s [%d/%d] %s\n", status, completed, total, result.Job.URL)
 		}
+		if jnl != nil {
+			if err := jnl.Append(*result); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+			}
+		}
+		// Stream each result as it completes so partial runs are not lost
+		if outputFormat == "jsonl" {
+			if err := formatter.WriteJSONLine(os.Stdout, *result); err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+			}
+		}
 	}
 
 	results := proc.Process(ctx, jobs, callback)
+	if len(restored) > 0 {
+		proc.RestoreHistory(restored)
+		results = append(restored, results...)
+	}
 
 	// Finish progress bar
 	if bar != nil {
@@ -165,20 +286,65 @@ func run(cmd *cobra.Command, args []string) error {
 		fmt.Fprintln(os.Stderr)
 	}
 
+	// An interrupted run has not reported anything unless it was streaming,
+	// so keep the old history
+	if seen != nil && (ctx.Err() == nil || outputFormat == "jsonl") {
+		if err := seen.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		}
+	}
+
 	// Output results
+	if err := writeOutput(formatter, tmpl, results); err != nil {
+		return err
+	}
+
+	if ctx.Err() != nil {
+		if jnl != nil {
+			fmt.Fprintf(os.Stderr, "⏸️  Run interrupted. Resume with: smart-digest --resume %s\n", jnl.ID())
+		}
+		return nil
+	}
+
+	// The run is complete, so there is nothing left to resume
+	if jnl != nil {
+		if err := jnl.Remove(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		}
+	}
+	sendNotifications(ctx, sinks, formatter, results)
+	return nil
+}
+
+// writeOutput writes the report to stdout in the selected format.
+func writeOutput(formatter *output.Formatter, tmpl *template.Template, results []processor.Result) error {
+	if tmpl != nil {
+		return formatter.FormatTemplate(os.Stdout, tmpl, results)
+	}
 	switch outputFormat {
+	case "jsonl":
+		// Already streamed by the callback
+		return nil
 	case "json":
 		return formatter.FormatJSON(os.Stdout, results)
+	case "html":
+		return formatter.FormatHTML(os.Stdout, results)
 	default:
 		return formatter.FormatMarkdown(os.Stdout, results)
 	}
 }
 
-// collectJobs gathers URLs from all input sources.
-func collectJobs(args []string) ([]processor.Job, error) {
+// collectJobs gathers URLs from all input sources. Feeds from the config
+// file are only read when nothing was given on the command line or stdin.
+func collectJobs(ctx context.Context, cfg *config.Config, args []string) ([]processor.Job, error) {
 	var jobs []processor.Job
 	parser := input.New()
 
+	since, err := input.ParseSince(sinceFlag, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	// From --url flag
 	if urlFlag != "" {
 		jobs = append(jobs, processor.Job{URL: urlFlag})
@@ -199,5 +365,63 @@ func collectJobs(args []string) ([]processor.Job, error) {
 		jobs = append(jobs, stdinJobs...)
 	}
 
+	// From --feed flags, falling back to configured feeds
+	var feeds []input.Feed
+	for _, feedURL := range feedFlags {
+		feeds = append(feeds, input.Feed{URL: feedURL})
+	}
+	if len(feeds) == 0 && len(jobs) == 0 {
+		feeds = configFeeds(cfg)
+	}
+
+	if len(feeds) > 0 {
+		reader, err := newFeedReader(cfg)
+		if err != nil {
+			return nil, err
+		}
+		feedJobs, errs := reader.ReadFeeds(ctx, feeds, since)
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		}
+		if verboseFlag {
+			fmt.Fprintf(os.Stderr, "📰 %d entries from %d feeds\n", len(feedJobs), len(feeds))
+		}
+		jobs = append(jobs, feedJobs...)
+	}
+
 	return jobs, nil
 }
+
+// configFeeds converts the feeds in cfg to input feeds.
+func configFeeds(cfg *config.Config) []input.Feed {
+	feeds := make([]input.Feed, 0, len(cfg.Feeds))
+	for _, fc := range cfg.Feeds {
+		feeds = append(feeds, input.Feed{
+			URL:      fc.URL,
+			Name:     fc.Name,
+			Project:  fc.Project,
+			MaxItems: fc.MaxItems,
+		})
+	}
+	return feeds
+}
+
+// signalContext returns a context cancelled on SIGINT or SIGTERM.
+func signalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Handle signals gracefully
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-sigChan:
+			fmt.Fprintln(os.Stderr, "\n⚠️  Interrupted, shutting down...")
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigChan)
+	}()
+
+	return ctx, cancel
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/taro33333/smart-digest/internal/config"
+	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/history"
+	"github.com/taro33333/smart-digest/internal/input"
+	"github.com/taro33333/smart-digest/internal/journal"
+	"github.com/taro33333/smart-digest/internal/llm"
+	"github.com/taro33333/smart-digest/internal/notify"
+	"github.com/taro33333/smart-digest/internal/output"
+	"github.com/taro33333/smart-digest/internal/processor"
+)
+
+// pipelineOptions carries the CLI switches that shape the processor.
+type pipelineOptions struct {
+	noCache bool // do not read or write the cache
+	refresh bool // ignore cached entries but store fresh results
+	onlyNew bool // skip articles already reported in earlier runs
+
+	limitReporter processor.LimitReporter // notified when adaptive limits change
+}
+
+// newProcessor builds the fetcher, LLM provider and processor from cfg.
+// The returned history store is nil when history is disabled; callers
+// save it once their results have been reported.
+func newProcessor(cfg *config.Config, opts pipelineOptions) (*processor.Processor, *history.Store, error) {
+	prompts, err := llm.LoadPrompts(cfg.Language, cfg.Prompts.System, cfg.Prompts.User)
+	if err != nil {
+		return nil, nil, fmt.Errorf("prompt template error: %w", err)
+	}
+
+	fetchOpts, err := fetcherOptions(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	procOpts := []processor.Option{
+		processor.WithPrompts(prompts),
+		processor.WithFetchLimits(cfg.Fetch.Workers, cfg.Fetch.RateLimit, cfg.Fetch.Burst),
+		processor.WithLLMLimits(cfg.RateBurst, cfg.TokensPerMinute),
+		processor.WithRetryPolicy(processor.RetryPolicy{
+			MaxAttempts: cfg.Retry.MaxAttempts,
+			BaseDelay:   cfg.Retry.BaseDelay,
+			MaxDelay:    cfg.Retry.MaxDelay,
+		}),
+	}
+	if cfg.Adaptive.Enabled {
+		procOpts = append(procOpts,
+			processor.WithAdaptiveLimits(cfg.Adaptive.MinRate, cfg.Adaptive.LatencyThreshold),
+			processor.WithLimitReporter(opts.limitReporter))
+	}
+	if cfg.Chunking.Enabled {
+		budget := cfg.ChunkBudget()
+		fetchOpts = append(fetchOpts, fetcher.WithMaxChars(budget*cfg.Chunking.MaxChunks))
+		procOpts = append(procOpts, processor.WithChunking(budget))
+	}
+	if cfg.Cache.Enabled && !opts.noCache {
+		store, err := openCache(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		procOpts = append(procOpts, processor.WithCache(store, cfg.ModelID(), opts.refresh))
+		if !opts.refresh {
+			fetchOpts = append(fetchOpts, fetcher.WithPageStore(store))
+		}
+	}
+
+	var seen *history.Store
+	if cfg.History.Enabled {
+		if seen, err = openHistory(cfg); err != nil {
+			return nil, nil, err
+		}
+		procOpts = append(procOpts, processor.WithHistory(seen, cfg.Threshold, opts.onlyNew))
+	}
+
+	f := fetcher.New(fetchOpts...)
+
+	provider, err := llm.NewProvider(cfg, prompts)
+	if err != nil {
+		return nil, nil, fmt.Errorf("LLM initialization error: %w", err)
+	}
+
+	proc := processor.New(f, provider, cfg.Interests, cfg.MaxWorkers, cfg.RateLimit, procOpts...)
+	return proc, seen, nil
+}
+
+// fetcherOptions returns the network policy and politeness options shared
+// by article and feed fetches.
+func fetcherOptions(cfg *config.Config) ([]fetcher.Option, error) {
+	policy, err := networkPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return []fetcher.Option{
+		fetcher.WithPolicy(policy),
+		fetcher.WithRobots(cfg.Politeness.Robots, cfg.Politeness.MaxCrawlDelay),
+		fetcher.WithHostLimits(cfg.Politeness.HostConcurrency, cfg.Politeness.HostInterval),
+	}, nil
+}
+
+// newFeedReader builds a feed reader that is subject to the network policy.
+func newFeedReader(cfg *config.Config) (*input.FeedReader, error) {
+	opts, err := fetcherOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return input.NewFeedReader(fetcher.New(opts...)), nil
+}
+
+// openHistory loads the seen-article history from cfg, defaulting to the XDG state dir.
+func openHistory(cfg *config.Config) (*history.Store, error) {
+	path := cfg.History.Path
+	if path == "" {
+		var err error
+		if path, err = history.DefaultPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	store, err := history.Open(path, cfg.History.Retention)
+	if err != nil {
+		return nil, fmt.Errorf("history error: %w", err)
+	}
+	return store, nil
+}
+
+// journalDir returns the run journal directory from cfg, defaulting to the XDG state dir.
+func journalDir(cfg *config.Config) (string, error) {
+	if cfg.Journal.Dir != "" {
+		return cfg.Journal.Dir, nil
+	}
+	return journal.DefaultDir()
+}
+
+// formatOptions returns the formatter options every report shares.
+func formatOptions(cfg *config.Config, opts ...output.Option) []output.Option {
+	return append([]output.Option{
+		output.WithRunInfo(string(cfg.LLMProvider), cfg.Model),
+		output.WithInterests(cfg.Interests),
+	}, opts...)
+}
+
+// newSinks builds the configured notification sinks.
+func newSinks(cfg *config.Config) ([]notify.Sink, error) {
+	var sinks []notify.Sink
+	for i, sc := range cfg.Notify.Sinks {
+		opts := []notify.Option{
+			notify.WithMaxAttempts(cfg.Notify.MaxAttempts),
+			notify.WithTimeout(cfg.Notify.Timeout),
+		}
+		if sc.Name != "" {
+			opts = append(opts, notify.WithName(sc.Name))
+		}
+
+		var sink notify.Sink
+		switch sc.Type {
+		case "slack":
+			sink = notify.NewSlack(sc.URL, opts...)
+		case "discord":
+			sink = notify.NewDiscord(sc.URL, opts...)
+		case "webhook":
+			sink = notify.NewWebhook(sc.URL, sc.Secret, opts...)
+		case "email":
+			email, err := notify.NewEmail(notify.EmailConfig{
+				Host:     sc.SMTP.Host,
+				Port:     sc.SMTP.Port,
+				TLS:      sc.SMTP.TLS,
+				Username: sc.SMTP.Username,
+				Password: sc.SMTP.Password,
+				From:     sc.From,
+				To:       sc.To,
+				Subject:  sc.Subject,
+			}, opts...)
+			if err != nil {
+				return nil, fmt.Errorf("notify.sinks[%d]: %w", i, err)
+			}
+			sink = email
+		}
+
+		if sc.SkipEmpty {
+			sink = notify.SkipEmpty(sink)
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// sendNotifications delivers the digest to every sink. Failures are
+// reported as warnings since the digest itself was already written.
+func sendNotifications(ctx context.Context, sinks []notify.Sink, formatter *output.Formatter, results []processor.Result) {
+	if len(sinks) == 0 {
+		return
+	}
+	if err := notify.SendAll(ctx, sinks, notify.NewDigest(formatter, results)); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  notification failed: %v\n", err)
+	}
+}
+
+// networkPolicy builds the fetcher's network policy, or nil when disabled.
+func networkPolicy(cfg *config.Config) (*fetcher.Policy, error) {
+	if !cfg.Network.Enabled {
+		return nil, nil
+	}
+	policy, err := fetcher.NewPolicy(fetcher.Rules{
+		AllowHosts: cfg.Network.AllowHosts,
+		DenyHosts:  cfg.Network.DenyHosts,
+		AllowCIDRs: cfg.Network.AllowCIDRs,
+		DenyCIDRs:  cfg.Network.DenyCIDRs,
+		AllowPorts: cfg.Network.AllowPorts,
+		DenyPorts:  cfg.Network.DenyPorts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("network policy error: %w", err)
+	}
+	return policy, nil
+}
+
+// reportLimits prints the effective LLM limits after an adaptive change.
+func reportLimits(state processor.LimitState) {
+	rate := "unlimited"
+	if state.Rate > 0 {
+		rate = fmt.Sprintf("%.2f req/s", state.Rate)
+	}
+	fmt.Fprintf(os.Stderr, "⚙️  LLM limits: %d concurrent, %s (%s)\n", state.Concurrency, rate, state.Reason)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/taro33333/smart-digest/internal/config"
+	"github.com/taro33333/smart-digest/internal/server"
+)
+
+// shutdownTimeout bounds how long the running job, and then in-flight
+// requests, may take after a signal.
+const shutdownTimeout = 10 * time.Second
+
+var addrFlag string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run an HTTP API for submitting URLs and fetching digests",
+	Long: `serve starts an HTTP server that queues submitted URLs, processes them
+with the configured LLM and returns reports in markdown, JSON or HTML.
+
+Jobs run strictly one at a time in submission order, sharing the configured
+workers and rate limits. On SIGINT or SIGTERM the server stops accepting jobs,
+fails the queued ones and gives the running job 10s to finish before
+interrupting it. A second signal exits immediately.
+
+Endpoints:
+  POST /v1/jobs               Submit URLs (same JSON / JSON Lines format as stdin)
+  GET  /v1/jobs/{id}          Poll job status
+  GET  /v1/jobs/{id}/events   Stream status updates (server-sent events)
+  GET  /v1/jobs/{id}/result   Fetch the report (?format=markdown|json|html)`,
+	Args: cobra.NoArgs,
+	RunE: serve,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&addrFlag, "addr", "", "Listen address (default from config, 127.0.0.1:8080)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func serve(cmd *cobra.Command, args []string) error {
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	if addrFlag != "" {
+		cfg.Server.Addr = addrFlag
+	}
+
+	proc, seen, err := newProcessor(cfg, pipelineOptions{})
+	if err != nil {
+		return err
+	}
+
+	opts := []server.Option{
+		server.WithLimits(cfg.Server.MaxBodyBytes, cfg.Server.MaxURLs),
+		server.WithQueueSize(cfg.Server.QueueSize),
+		server.WithJobTTL(cfg.Server.JobTTL),
+		server.WithFormatOptions(formatOptions(cfg)...),
+	}
+	if seen != nil {
+		opts = append(opts, server.WithHistory(seen))
+	}
+	srv := server.New(proc, cfg.Threshold, opts...)
+
+	streamCtx, stopStreams := context.WithCancel(context.Background())
+	defer stopStreams()
+
+	httpServer := &http.Server{
+		Addr:              cfg.Server.Addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		// Cancelling streamCtx ends event streams so Shutdown does not wait on them
+		BaseContext: func(net.Listener) context.Context { return streamCtx },
+	}
+
+	// The running job is cancelled through srv.Shutdown, not by the signal
+	go srv.Run(context.Background())
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+	fmt.Fprintf(os.Stderr, "🌐 Listening on http://%s\n", cfg.Server.Addr)
+
+	select {
+	case err = <-serveErr:
+		// Nothing can poll the results any more
+		interruptCtx, stop := context.WithCancel(context.Background())
+		stop()
+		_ = srv.Shutdown(interruptCtx)
+	case <-ctx.Done():
+		jobCtx, stop := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer stop()
+		if srv.Shutdown(jobCtx) != nil {
+			fmt.Fprintln(os.Stderr, "⚠️  Running job did not finish in time and was interrupted")
+		}
+
+		// Let clients watching the job receive its final status
+		stopStreams()
+		httpCtx, stopHTTP := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer stopHTTP()
+		err = httpServer.Shutdown(httpCtx)
+	}
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("server error: %w", err)
+	}
+	return nil
+}
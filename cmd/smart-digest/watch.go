@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/taro33333/smart-digest/internal/config"
+	"github.com/taro33333/smart-digest/internal/history"
+	"github.com/taro33333/smart-digest/internal/input"
+	"github.com/taro33333/smart-digest/internal/notify"
+	"github.com/taro33333/smart-digest/internal/output"
+	"github.com/taro33333/smart-digest/internal/processor"
+	"github.com/taro33333/smart-digest/internal/watch"
+)
+
+var (
+	scheduleFlag  string
+	outputDirFlag string
+	nowFlag       bool
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Run digests on a schedule and write dated reports",
+	Long: `watch keeps running and, on every scheduled run, reads the configured
+feeds and URL files, analyzes the entries and writes a dated report to the
+output directory. Failed runs are retried with backoff, and a lock file
+prevents two instances from running against the same directory.
+
+The schedule is a cron expression or an interval:
+  smart-digest watch --schedule "0 9 * * 1-5"   # 9:00 on weekdays
+  smart-digest watch --schedule 6h              # every 6 hours`,
+	Args: cobra.NoArgs,
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().StringVar(&scheduleFlag, "schedule", "", "Cron expression or interval (default from config)")
+	watchCmd.Flags().StringVarP(&outputDirFlag, "output-dir", "o", "", "Directory for dated reports (default from config)")
+	watchCmd.Flags().BoolVar(&noNotifyFlag, "no-notify", false, "Do not send reports to the configured notification sinks")
+	watchCmd.Flags().BoolVar(&nowFlag, "now", false, "Run once immediately instead of waiting for the first scheduled time")
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	ctx, cancel := signalContext()
+	defer cancel()
+
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		return fmt.Errorf("configuration error: %w", err)
+	}
+	if scheduleFlag != "" {
+		cfg.Watch.Schedule = scheduleFlag
+	}
+	if outputDirFlag != "" {
+		cfg.Watch.OutputDir = outputDirFlag
+	}
+	if cfg.Watch.OnlyNew && !cfg.History.Enabled {
+		return fmt.Errorf("watch.only_new requires history.enabled in the config")
+	}
+
+	sched, err := watch.Parse(cfg.Watch.Schedule)
+	if err != nil {
+		return err
+	}
+
+	lockPath := cfg.Watch.LockFile
+	if lockPath == "" {
+		lockPath = filepath.Join(cfg.Watch.OutputDir, ".smart-digest.lock")
+	}
+	lock, err := watch.Acquire(lockPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	var tmpl *template.Template
+	if cfg.Watch.Template != "" {
+		if tmpl, err = output.LoadTemplate(cfg.Watch.Template); err != nil {
+			return err
+		}
+	}
+
+	proc, seen, err := newProcessor(cfg, pipelineOptions{onlyNew: cfg.Watch.OnlyNew})
+	if err != nil {
+		return err
+	}
+	reader, err := newFeedReader(cfg)
+	if err != nil {
+		return err
+	}
+
+	var sinks []notify.Sink
+	if !noNotifyFlag {
+		if sinks, err = newSinks(cfg); err != nil {
+			return err
+		}
+	}
+
+	next := sched.Next(time.Now())
+	if nowFlag {
+		next = time.Now()
+	}
+	retryDelay := cfg.Watch.RetryDelay
+
+	for {
+		if next.IsZero() {
+			return fmt.Errorf("schedule %q never fires", cfg.Watch.Schedule)
+		}
+		fmt.Fprintf(os.Stderr, "🕒 Next run at %s\n", next.Format("2006-01-02 15:04"))
+		if !sleepUntil(ctx, next) {
+			return nil
+		}
+
+		path, err := digestOnce(ctx, cfg, reader, proc, seen, tmpl, sinks)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		now := time.Now()
+		next = sched.Next(now)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Run failed: %v\n", err)
+			if retry := now.Add(retryDelay); next.IsZero() || retry.Before(next) {
+				next = retry
+			}
+			retryDelay = min(retryDelay*2, cfg.Watch.MaxRetryDelay)
+			continue
+		}
+
+		retryDelay = cfg.Watch.RetryDelay
+		if path == "" {
+			fmt.Fprintln(os.Stderr, "📭 No entries to report")
+		} else {
+			fmt.Fprintf(os.Stderr, "✅ Wrote %s\n", path)
+		}
+	}
+}
+
+// digestOnce runs one scheduled digest and returns the report path, or ""
+// when there was nothing to process. A non-nil tmpl replaces watch.format.
+// The report is sent to sinks once it has been written.
+func digestOnce(ctx context.Context, cfg *config.Config, reader *input.FeedReader, proc *processor.Processor, seen *history.Store, tmpl *template.Template, sinks []notify.Sink) (string, error) {
+	jobs, err := watchJobs(ctx, cfg, reader)
+	if err != nil {
+		return "", fmt.Errorf("input error: %w", err)
+	}
+	if len(jobs) == 0 {
+		return "", nil
+	}
+
+	results := proc.Process(ctx, jobs, nil)
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	failed := 0
+	var lastErr error
+	for _, r := range results {
+		if r.Error != nil {
+			failed++
+			lastErr = r.Error
+		}
+	}
+	if failed == len(results) {
+		return "", fmt.Errorf("all %d articles failed, last error: %w", failed, lastErr)
+	}
+
+	formatter := output.New(cfg.Threshold, formatOptions(cfg, output.WithGroupBy(output.GroupBy(cfg.Watch.GroupBy)))...)
+	write, ext := formatter.FormatMarkdown, ".md"
+	switch cfg.Watch.Format {
+	case "json":
+		write, ext = formatter.FormatJSON, ".json"
+	case "html":
+		write, ext = formatter.FormatHTML, ".html"
+	}
+	if tmpl != nil {
+		write, ext = func(w io.Writer, results []processor.Result) error {
+			return formatter.FormatTemplate(w, tmpl, results)
+		}, templateExt(cfg.Watch.Template)
+	}
+
+	path, err := writeReport(cfg.Watch.OutputDir, ext, func(w io.Writer) error {
+		return write(w, results)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Only mark articles as reported once the report exists
+	if seen != nil {
+		if err := seen.Save(); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		}
+	}
+
+	sendNotifications(ctx, sinks, formatter, results)
+	return path, nil
+}
+
+// watchJobs reads the configured feeds with reader and the URL files.
+// Failing feeds are reported as warnings unless nothing could be read at all.
+func watchJobs(ctx context.Context, cfg *config.Config, reader *input.FeedReader) ([]processor.Job, error) {
+	var jobs []processor.Job
+	parser := input.New()
+
+	for _, path := range cfg.Watch.URLFiles {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open URL file: %w", err)
+		}
+		fileJobs, err := parser.ParseStdin(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		jobs = append(jobs, fileJobs...)
+	}
+
+	if feeds := configFeeds(cfg); len(feeds) > 0 {
+		feedJobs, errs := reader.ReadFeeds(ctx, feeds, time.Time{})
+		if len(jobs) == 0 && len(feedJobs) == 0 && len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		for _, err := range errs {
+			fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+		}
+		jobs = append(jobs, feedJobs...)
+	}
+
+	return jobs, nil
+}
+
+// writeReport atomically writes a report named after today's date into dir,
+// adding the time when a report for today already exists.
+func writeReport(dir, ext string, write func(io.Writer) error) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	now := time.Now()
+	path := filepath.Join(dir, "digest-"+now.Format("2006-01-02")+ext)
+	if _, err := os.Stat(path); err == nil {
+		path = filepath.Join(dir, "digest-"+now.Format("2006-01-02-1504")+ext)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".digest-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// CreateTemp uses 0600; reports are meant to be shared
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("failed to write report: %w", err)
+	}
+	return path, nil
+}
+
+// templateExt derives the report extension from a template file name,
+// e.g. "wiki.md.tmpl" gives ".md". Other names default to ".txt".
+func templateExt(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), ".tmpl")
+	if ext := filepath.Ext(name); ext != "" {
+		return ext
+	}
+	return ".txt"
+}
+
+// sleepUntil waits for t and reports false if ctx was cancelled first.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	timer := time.NewTimer(time.Until(t))
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
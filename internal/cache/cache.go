@@ -0,0 +1,320 @@
+// Package cache provides an on-disk cache for fetched articles and LLM analyses.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/llm"
+	"github.com/taro33333/smart-digest/internal/urlnorm"
+)
+
+// Entry kinds, also used as subdirectory names.
+const (
+	kindArticles = "articles"
+	kindAnalyses = "analyses"
+	kindPages    = "pages"
+)
+
+// Cache stores articles and analyses as JSON files under a directory.
+// Articles are keyed by normalized URL; analyses additionally by content
+// hash, model and prompt version so any change invalidates them. Pages
+// (HTTP validators plus the extracted article) let the fetcher revalidate
+// expired articles with a conditional request; they share the analysis TTL.
+type Cache struct {
+	dir         string
+	articleTTL  time.Duration
+	analysisTTL time.Duration
+}
+
+// AnalysisKey identifies a cached analysis.
+type AnalysisKey struct {
+	URL           string
+	ContentHash   string
+	Model         string // provider/model identity, e.g. "openai/gpt-4o-mini"
+	PromptVersion string
+}
+
+// entry is the on-disk envelope for every cached value.
+type entry struct {
+	Key       string          `json:"key"`
+	CreatedAt time.Time       `json:"created_at"`
+	Value     json.RawMessage `json:"value"`
+}
+
+// cachedAnalysis keeps the backend attribution that AnalysisResult omits from JSON.
+type cachedAnalysis struct {
+	llm.AnalysisResult
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+}
+
+// DefaultDir returns the XDG cache directory for smart-digest.
+func DefaultDir() (string, error) {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	return filepath.Join(base, "smart-digest"), nil
+}
+
+// Open creates the cache directory if needed and returns a Cache.
+func Open(dir string, articleTTL, analysisTTL time.Duration) (*Cache, error) {
+	for _, kind := range []string{kindArticles, kindAnalyses, kindPages} {
+		if err := os.MkdirAll(filepath.Join(dir, kind), 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create cache directory: %w", err)
+		}
+	}
+
+	return &Cache{
+		dir:         dir,
+		articleTTL:  articleTTL,
+		analysisTTL: analysisTTL,
+	}, nil
+}
+
+// Dir returns the cache directory.
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// GetArticle returns a cached article for the URL if it has not expired.
+func (c *Cache) GetArticle(rawURL string) (*fetcher.Article, bool) {
+	var article fetcher.Article
+	if !c.get(kindArticles, urlnorm.Normalize(rawURL), c.articleTTL, &article) {
+		return nil, false
+	}
+	return &article, true
+}
+
+// PutArticle stores an article under its URL.
+func (c *Cache) PutArticle(article *fetcher.Article) error {
+	return c.put(kindArticles, urlnorm.Normalize(article.URL), article)
+}
+
+// GetAnalysis returns a cached analysis for the key if it has not expired.
+func (c *Cache) GetAnalysis(key AnalysisKey) (*llm.AnalysisResult, bool) {
+	var cached cachedAnalysis
+	if !c.get(kindAnalyses, key.String(), c.analysisTTL, &cached) {
+		return nil, false
+	}
+
+	result := cached.AnalysisResult
+	result.Provider = cached.Provider
+	result.Model = cached.Model
+	return &result, true
+}
+
+// PutAnalysis stores an analysis under the key.
+func (c *Cache) PutAnalysis(key AnalysisKey, result *llm.AnalysisResult) error {
+	return c.put(kindAnalyses, key.String(), cachedAnalysis{
+		AnalysisResult: *result,
+		Provider:       result.Provider,
+		Model:          result.Model,
+	})
+}
+
+// GetPage returns the stored validators and article for the URL.
+// It implements fetcher.PageStore.
+func (c *Cache) GetPage(rawURL string) (*fetcher.Page, bool) {
+	var page fetcher.Page
+	if !c.get(kindPages, urlnorm.Normalize(rawURL), c.analysisTTL, &page) {
+		return nil, false
+	}
+	return &page, true
+}
+
+// PutPage stores validators and the extracted article for the URL.
+func (c *Cache) PutPage(rawURL string, page *fetcher.Page) error {
+	return c.put(kindPages, urlnorm.Normalize(rawURL), page)
+}
+
+// String returns the canonical form hashed into the file name.
+func (k AnalysisKey) String() string {
+	return strings.Join([]string{urlnorm.Normalize(k.URL), k.ContentHash, k.Model, k.PromptVersion}, "\n")
+}
+
+// get decodes the entry for key into v. Missing, corrupt and expired
+// entries are all reported as a miss.
+func (c *Cache) get(kind, key string, ttl time.Duration, v any) bool {
+	data, err := os.ReadFile(c.path(kind, key))
+	if err != nil {
+		return false
+	}
+
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil || e.Key != key {
+		return false
+	}
+
+	if ttl > 0 && time.Since(e.CreatedAt) > ttl {
+		return false
+	}
+
+	return json.Unmarshal(e.Value, v) == nil
+}
+
+// put writes v atomically so concurrent readers never see partial files.
+func (c *Cache) put(kind, key string, v any) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	data, err := json.Marshal(entry{Key: key, CreatedAt: time.Now(), Value: value})
+	if err != nil {
+		return fmt.Errorf("failed to encode cache entry: %w", err)
+	}
+
+	path := c.path(kind, key)
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to write cache entry: %w", err)
+	}
+	return nil
+}
+
+// path returns the file for key.
+func (c *Cache) path(kind, key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, kind, hex.EncodeToString(sum[:])+".json")
+}
+
+// KindStats summarizes one kind of cache entry.
+type KindStats struct {
+	Entries int
+	Expired int
+	Bytes   int64
+}
+
+// Stats summarizes the cache contents.
+type Stats struct {
+	Articles KindStats
+	Analyses KindStats
+	Pages    KindStats
+}
+
+// Stats walks the cache directory and counts entries.
+func (c *Cache) Stats() (Stats, error) {
+	var stats Stats
+	var err error
+
+	if stats.Articles, err = c.walk(kindArticles, c.articleTTL, false); err != nil {
+		return stats, err
+	}
+	if stats.Analyses, err = c.walk(kindAnalyses, c.analysisTTL, false); err != nil {
+		return stats, err
+	}
+	if stats.Pages, err = c.walk(kindPages, c.analysisTTL, false); err != nil {
+		return stats, err
+	}
+	return stats, nil
+}
+
+// Prune removes expired entries and returns how many were deleted.
+func (c *Cache) Prune() (int, error) {
+	articles, err := c.walk(kindArticles, c.articleTTL, true)
+	if err != nil {
+		return 0, err
+	}
+	analyses, err := c.walk(kindAnalyses, c.analysisTTL, true)
+	if err != nil {
+		return articles.Expired, err
+	}
+	pages, err := c.walk(kindPages, c.analysisTTL, true)
+	if err != nil {
+		return articles.Expired + analyses.Expired, err
+	}
+	return articles.Expired + analyses.Expired + pages.Expired, nil
+}
+
+// Clear removes every cache entry.
+func (c *Cache) Clear() error {
+	for _, kind := range []string{kindArticles, kindAnalyses, kindPages} {
+		dir := filepath.Join(c.dir, kind)
+		if err := os.RemoveAll(dir); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// walk counts entries of a kind, deleting expired or unreadable ones when prune is set.
+func (c *Cache) walk(kind string, ttl time.Duration, prune bool) (KindStats, error) {
+	var stats KindStats
+
+	files, err := os.ReadDir(filepath.Join(c.dir, kind))
+	if errors.Is(err, fs.ErrNotExist) {
+		return stats, nil
+	}
+	if err != nil {
+		return stats, fmt.Errorf("failed to read cache: %w", err)
+	}
+
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(c.dir, kind, file.Name())
+
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		stats.Entries++
+		stats.Bytes += info.Size()
+
+		if !expired(path, ttl) {
+			continue
+		}
+		stats.Expired++
+		if prune {
+			if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return stats, fmt.Errorf("failed to prune cache: %w", err)
+			}
+		}
+	}
+
+	return stats, nil
+}
+
+// expired reports whether the entry at path is past ttl or unreadable.
+func expired(path string, ttl time.Duration) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return true
+	}
+
+	var e struct {
+		CreatedAt time.Time `json:"created_at"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return true
+	}
+	return ttl > 0 && time.Since(e.CreatedAt) > ttl
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,38 +15,323 @@ import (
 type LLMProvider string
 
 const (
-	ProviderOpenAI LLMProvider = "openai"
-	ProviderOllama LLMProvider = "ollama"
+	ProviderOpenAI    LLMProvider = "openai"
+	ProviderOllama    LLMProvider = "ollama"
+	ProviderAnthropic LLMProvider = "anthropic"
 )
 
 // Config holds all configuration for smart-digest.
 type Config struct {
-	LLMProvider LLMProvider `yaml:"llm_provider"`
-	APIKey      string      `yaml:"api_key"`
-	Model       string      `yaml:"model"`
-	Interests   []string    `yaml:"interests"`
-	Threshold   int         `yaml:"threshold"`
-	OllamaURL   string      `yaml:"ollama_url"`
-	MaxWorkers  int         `yaml:"max_workers"`
-	RateLimit   float64     `yaml:"rate_limit_per_second"`
+	LLMProvider     LLMProvider  `yaml:"llm_provider"`
+	APIKey          string       `yaml:"api_key"`
+	Model           string       `yaml:"model"`
+	OpenAI          OpenAIConfig `yaml:"openai"`
+	Interests       []string     `yaml:"interests"`
+	Threshold       int          `yaml:"threshold"`
+	OllamaURL       string       `yaml:"ollama_url"`
+	AnthropicAPIKey string       `yaml:"anthropic_api_key"`
+	AnthropicURL    string       `yaml:"anthropic_url"`
+
+	// MaxWorkers, RateLimit, RateBurst and TokensPerMinute limit the LLM
+	// (analyze) stage; the fetch stage is configured in Fetch.
+	MaxWorkers      int            `yaml:"max_workers"`
+	RateLimit       float64        `yaml:"rate_limit_per_second"`
+	RateBurst       int            `yaml:"rate_limit_burst"`
+	TokensPerMinute int            `yaml:"tokens_per_minute"` // estimated prompt tokens, 0 disables
+	Adaptive        AdaptiveConfig `yaml:"adaptive"`
+	Fetch           FetchConfig    `yaml:"fetch"`
+
+	// StructuredOutput asks backends that support it to constrain responses
+	// to the analysis JSON schema.
+	StructuredOutput bool `yaml:"structured_output"`
+
+	// Language selects the built-in prompts ("ja" or "en").
+	Language string        `yaml:"language"`
+	Prompts  PromptsConfig `yaml:"prompts"`
+
+	Retry    RetryConfig    `yaml:"retry"`
+	Chunking ChunkingConfig `yaml:"chunking"`
+
+	// Fallback lists providers tried in order after llm_provider fails with
+	// a rate-limit, transient or auth error.
+	Fallback []FallbackConfig `yaml:"fallback"`
+
+	Cache   CacheConfig   `yaml:"cache"`
+	History HistoryConfig `yaml:"history"`
+	Journal JournalConfig `yaml:"journal"`
+
+	// Server configures `smart-digest serve`.
+	Server ServerConfig `yaml:"server"`
+
+	// Watch configures `smart-digest watch`.
+	Watch WatchConfig `yaml:"watch"`
+
+	// Network restricts which destinations articles are fetched from.
+	Network NetworkConfig `yaml:"network"`
+
+	// Politeness limits how hard a single site is hit while fetching articles.
+	Politeness PolitenessConfig `yaml:"politeness"`
+
+	// Notify sends the digest to chat services and webhooks after a run.
+	Notify NotifyConfig `yaml:"notify"`
+
+	// Feeds are read when no URLs are given on the command line or stdin.
+	Feeds []FeedConfig `yaml:"feeds"`
+}
+
+// FeedConfig describes an RSS, Atom or JSON Feed source.
+type FeedConfig struct {
+	URL      string `yaml:"url"`
+	Name     string `yaml:"name"`      // defaults to the feed title
+	Project  string `yaml:"project"`   // attached to every entry
+	MaxItems int    `yaml:"max_items"` // 0 means no limit
+}
+
+// CacheConfig controls the on-disk article and analysis cache.
+type CacheConfig struct {
+	Enabled     bool          `yaml:"enabled"`
+	Dir         string        `yaml:"dir"`          // defaults to the XDG cache dir
+	ArticleTTL  time.Duration `yaml:"article_ttl"`  // how long fetched pages are reused
+	AnalysisTTL time.Duration `yaml:"analysis_ttl"` // how long LLM results are reused for unchanged content
+}
+
+// HistoryConfig controls the record of articles seen and reported in earlier runs.
+type HistoryConfig struct {
+	Enabled   bool          `yaml:"enabled"`
+	Path      string        `yaml:"path"`      // defaults to the XDG state dir
+	Retention time.Duration `yaml:"retention"` // records not seen for this long are dropped, 0 keeps all
+}
+
+// JournalConfig controls the per-run journal used by --resume.
+type JournalConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Dir     string `yaml:"dir"` // defaults to the XDG state dir
+}
+
+// ServerConfig controls the HTTP API server.
+type ServerConfig struct {
+	Addr         string        `yaml:"addr"`
+	MaxBodyBytes int64         `yaml:"max_body_bytes"` // per submission
+	MaxURLs      int           `yaml:"max_urls"`       // per submission
+	QueueSize    int           `yaml:"queue_size"`     // jobs waiting before submissions get 503
+	JobTTL       time.Duration `yaml:"job_ttl"`        // how long finished jobs can be polled
+}
+
+// WatchConfig controls scheduled digest runs.
+type WatchConfig struct {
+	Schedule  string   `yaml:"schedule"`   // cron expression ("0 9 * * *") or interval ("6h")
+	OutputDir string   `yaml:"output_dir"` // dated reports are written here
+	Format    string   `yaml:"format"`     // "markdown", "json" or "html"
+	Template  string   `yaml:"template"`   // text/template file used instead of format
+	GroupBy   string   `yaml:"group_by"`   // "category", "project", "interest" or "source"
+	URLFiles  []string `yaml:"url_files"`  // read every run, same format as stdin
+	OnlyNew   bool     `yaml:"only_new"`   // skip articles reported in earlier runs
+	LockFile  string   `yaml:"lock_file"`  // defaults to output_dir/.smart-digest.lock
+
+	// A failed run is retried after RetryDelay, doubling up to MaxRetryDelay,
+	// unless the next scheduled run comes first.
+	RetryDelay    time.Duration `yaml:"retry_delay"`
+	MaxRetryDelay time.Duration `yaml:"max_retry_delay"`
+}
+
+// NetworkConfig is the fetcher's network policy. Private, loopback and
+// link-local addresses are blocked unless listed in AllowCIDRs.
+type NetworkConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	AllowHosts []string `yaml:"allow_hosts"` // when set, only these hosts ("*.example.com" for subdomains)
+	DenyHosts  []string `yaml:"deny_hosts"`
+	AllowCIDRs []string `yaml:"allow_cidrs"` // exempt from the private-range block
+	DenyCIDRs  []string `yaml:"deny_cidrs"`  // always blocked
+	AllowPorts []int    `yaml:"allow_ports"` // when set, only these ports
+	DenyPorts  []int    `yaml:"deny_ports"`
+}
+
+// AdaptiveConfig lets the LLM concurrency and rate shrink on rate-limit
+// errors or slow responses and recover on success, with max_workers and
+// rate_limit_per_second as the upper bounds.
+type AdaptiveConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	MinRate          float64       `yaml:"min_rate_per_second"`
+	LatencyThreshold time.Duration `yaml:"latency_threshold"` // slower calls shrink the concurrency, 0 disables
+}
+
+// FetchConfig limits the stage that downloads and extracts articles.
+type FetchConfig struct {
+	Workers   int     `yaml:"workers"`
+	RateLimit float64 `yaml:"rate_limit_per_second"` // across all hosts, 0 disables
+	Burst     int     `yaml:"burst"`
+}
+
+// PolitenessConfig controls robots.txt handling and per-host request limits.
+// It is independent of rate_limit_per_second, which paces the LLM.
+type PolitenessConfig struct {
+	Robots          bool          `yaml:"robots"`           // honor robots.txt
+	MaxCrawlDelay   time.Duration `yaml:"max_crawl_delay"`  // longer Crawl-delay values are capped
+	HostConcurrency int           `yaml:"host_concurrency"` // requests in flight per host
+	HostInterval    time.Duration `yaml:"host_interval"`    // minimum time between requests to a host
+}
+
+// NotifyConfig lists the sinks that receive each finished digest.
+type NotifyConfig struct {
+	Sinks       []SinkConfig  `yaml:"sinks"`
+	MaxAttempts int           `yaml:"max_attempts"` // per message, including the first
+	Timeout     time.Duration `yaml:"timeout"`      // per request
+}
+
+// SinkConfig describes a single notification target. URL, Secret and the
+// SMTP credentials may reference environment variables as ${NAME}.
+type SinkConfig struct {
+	Type      string `yaml:"type"`       // "slack", "discord", "webhook" or "email"
+	Name      string `yaml:"name"`       // shown in error messages, defaults to type
+	URL       string `yaml:"url"`        // incoming webhook or endpoint URL
+	Secret    string `yaml:"secret"`     // webhook only: HMAC-SHA256 signing key
+	SkipEmpty bool   `yaml:"skip_empty"` // do not send when no article passes the threshold
+
+	// Email only
+	SMTP    SMTPConfig `yaml:"smtp"`
+	From    string     `yaml:"from"`
+	To      []string   `yaml:"to"`
+	Subject string     `yaml:"subject"` // text/template with .Date, .Count, .Processed, .Failed, .Threshold
+}
+
+// SMTPConfig is the mail server used by email sinks. Username and Password
+// fall back to SMTP_USERNAME and SMTP_PASSWORD.
+type SMTPConfig struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"` // defaults to 465 for tls, 587 otherwise
+	TLS      string `yaml:"tls"`  // "starttls" (default), "tls" or "none"
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+}
+
+// FallbackConfig selects a backup provider and the model to use with it.
+// Connection settings (keys, URLs) are shared with the primary provider config.
+type FallbackConfig struct {
+	Provider LLMProvider `yaml:"provider"`
+	Model    string      `yaml:"model"`
+}
+
+// PromptsConfig points at user-supplied prompt template files (Go text/template).
+// Empty paths use the built-in templates for the configured language.
+type PromptsConfig struct {
+	System string `yaml:"system"`
+	User   string `yaml:"user"`
+}
+
+// ChunkingConfig controls map-reduce summarization of long articles.
+type ChunkingConfig struct {
+	Enabled    bool `yaml:"enabled"`
+	ChunkChars int  `yaml:"chunk_chars"` // per-chunk budget in bytes
+	MaxChunks  int  `yaml:"max_chunks"`  // content beyond chunk_chars*max_chunks is truncated
+
+	// Budgets overrides chunk_chars per model or provider name, so small
+	// local models and long-context models can share one config.
+	Budgets map[string]int `yaml:"budgets"`
+}
+
+// RetryConfig controls retries of failed LLM calls.
+type RetryConfig struct {
+	MaxAttempts int           `yaml:"max_attempts"`
+	BaseDelay   time.Duration `yaml:"base_delay"`
+	MaxDelay    time.Duration `yaml:"max_delay"`
+}
+
+// OpenAIConfig holds settings for OpenAI-compatible endpoints
+// (Azure OpenAI, vLLM, LM Studio, LiteLLM, internal gateways).
+type OpenAIConfig struct {
+	BaseURL      string            `yaml:"base_url"`
+	APIType      string            `yaml:"api_type"` // "openai" (default) or "azure"
+	APIVersion   string            `yaml:"api_version"`
+	Deployment   string            `yaml:"deployment"`
+	Organization string            `yaml:"organization"`
+	Headers      map[string]string `yaml:"headers"`
 }
 
 // DefaultConfig returns a configuration with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
-		LLMProvider: ProviderOpenAI,
-		Model:       "gpt-4o-mini",
-		Interests:   []string{"Go", "Rust", "Productivity", "System Design"},
-		Threshold:   70,
-		OllamaURL:   "http://localhost:11434",
-		MaxWorkers:  5,
-		RateLimit:   10.0,
+		LLMProvider:  ProviderOpenAI,
+		Model:        "gpt-4o-mini",
+		Interests:    []string{"Go", "Rust", "Productivity", "System Design"},
+		Threshold:    70,
+		OllamaURL:    "http://localhost:11434",
+		AnthropicURL: "https://api.anthropic.com",
+		MaxWorkers:   5,
+		RateLimit:    10.0,
+		RateBurst:    1,
+		Adaptive: AdaptiveConfig{
+			Enabled:          true,
+			MinRate:          0.05,
+			LatencyThreshold: time.Minute,
+		},
+		Fetch: FetchConfig{
+			Workers: 8,
+			Burst:   1,
+		},
+
+		StructuredOutput: true,
+		Language:         "ja",
+
+		Retry: RetryConfig{
+			MaxAttempts: 3,
+			BaseDelay:   time.Second,
+			MaxDelay:    30 * time.Second,
+		},
+		Chunking: ChunkingConfig{
+			ChunkChars: 15000,
+			MaxChunks:  8,
+		},
+		Cache: CacheConfig{
+			ArticleTTL:  12 * time.Hour,
+			AnalysisTTL: 30 * 24 * time.Hour,
+		},
+		History: HistoryConfig{
+			Retention: 90 * 24 * time.Hour,
+		},
+		Server: ServerConfig{
+			Addr:         "127.0.0.1:8080",
+			MaxBodyBytes: 1 << 20,
+			MaxURLs:      100,
+			QueueSize:    16,
+			JobTTL:       time.Hour,
+		},
+		Watch: WatchConfig{
+			Schedule:      "0 9 * * *",
+			OutputDir:     "digests",
+			Format:        "markdown",
+			RetryDelay:    time.Minute,
+			MaxRetryDelay: time.Hour,
+		},
+		Network: NetworkConfig{
+			Enabled: true,
+		},
+		Politeness: PolitenessConfig{
+			Robots:          true,
+			MaxCrawlDelay:   30 * time.Second,
+			HostConcurrency: 2,
+			HostInterval:    time.Second,
+		},
+		Notify: NotifyConfig{
+			MaxAttempts: 3,
+			Timeout:     10 * time.Second,
+		},
 	}
 }
 
 // Load reads configuration from file, checking multiple locations.
 // Priority: ./config.yaml > ~/.config/smart-digest/config.yaml > defaults
 func Load(customPath string) (*Config, error) {
+	return load(customPath, true)
+}
+
+// Read reads configuration like Load but does not validate it, so
+// maintenance commands that never call an LLM work without credentials.
+func Read(customPath string) (*Config, error) {
+	return load(customPath, false)
+}
+
+// load implements Load and Read.
+func load(customPath string, validate bool) (*Config, error) {
 	cfg := DefaultConfig()
 
 	// Determine config path
@@ -71,9 +358,31 @@ func Load(customPath string) (*Config, error) {
 	if envKey := os.Getenv("OPENAI_API_KEY"); envKey != "" && cfg.APIKey == "" {
 		cfg.APIKey = envKey
 	}
+	if envKey := os.Getenv("ANTHROPIC_API_KEY"); envKey != "" && cfg.AnthropicAPIKey == "" {
+		cfg.AnthropicAPIKey = envKey
+	}
 
-	if err := cfg.Validate(); err != nil {
-		return nil, err
+	// Webhook URLs are credentials, so allow keeping them out of the file
+	for i := range cfg.Notify.Sinks {
+		sink := &cfg.Notify.Sinks[i]
+		sink.URL = os.ExpandEnv(sink.URL)
+		sink.Secret = os.ExpandEnv(sink.Secret)
+		sink.SMTP.Username = os.ExpandEnv(sink.SMTP.Username)
+		sink.SMTP.Password = os.ExpandEnv(sink.SMTP.Password)
+		if sink.Type == "email" {
+			if sink.SMTP.Username == "" {
+				sink.SMTP.Username = os.Getenv("SMTP_USERNAME")
+			}
+			if sink.SMTP.Password == "" {
+				sink.SMTP.Password = os.Getenv("SMTP_PASSWORD")
+			}
+		}
+	}
+
+	if validate {
+		if err := cfg.Validate(); err != nil {
+			return nil, err
+		}
 	}
 
 	return cfg, nil
@@ -102,22 +411,47 @@ func findConfigFile() string {
 
 // Validate checks if the configuration is valid.
 func (c *Config) Validate() error {
-	if c.LLMProvider != ProviderOpenAI && c.LLMProvider != ProviderOllama {
-		return fmt.Errorf("invalid llm_provider: %s (must be 'openai' or 'ollama')", c.LLMProvider)
+	if err := c.validateProvider("llm_provider", c.LLMProvider); err != nil {
+		return err
 	}
 
-	if c.LLMProvider == ProviderOpenAI && c.APIKey == "" {
-		return fmt.Errorf("api_key is required for OpenAI provider")
+	switch c.OpenAI.APIType {
+	case "", "openai":
+	case "azure":
+		if c.OpenAI.BaseURL == "" {
+			return fmt.Errorf("openai.base_url is required when openai.api_type is 'azure'")
+		}
+	default:
+		return fmt.Errorf("invalid openai.api_type: %s (must be 'openai' or 'azure')", c.OpenAI.APIType)
 	}
 
 	if c.Model == "" {
 		return fmt.Errorf("model must be specified")
 	}
 
+	for i, fb := range c.Fallback {
+		if err := c.validateProvider(fmt.Sprintf("fallback[%d].provider", i), fb.Provider); err != nil {
+			return err
+		}
+		if fb.Model == "" {
+			return fmt.Errorf("fallback[%d].model must be specified", i)
+		}
+	}
+
+	for i, feed := range c.Feeds {
+		if feed.URL == "" {
+			return fmt.Errorf("feeds[%d].url must be specified", i)
+		}
+	}
+
 	if len(c.Interests) == 0 {
 		return fmt.Errorf("at least one interest must be specified")
 	}
 
+	if c.Language != "ja" && c.Language != "en" {
+		return fmt.Errorf("invalid language: %s (must be 'ja' or 'en')", c.Language)
+	}
+
 	if c.Threshold < 0 || c.Threshold > 100 {
 		return fmt.Errorf("threshold must be between 0 and 100")
 	}
@@ -130,6 +464,155 @@ func (c *Config) Validate() error {
 		c.RateLimit = 10.0
 	}
 
+	if c.RateBurst < 1 {
+		c.RateBurst = 1
+	}
+
+	if c.TokensPerMinute < 0 {
+		return fmt.Errorf("tokens_per_minute must not be negative")
+	}
+
+	if c.Adaptive.MinRate <= 0 {
+		c.Adaptive.MinRate = 0.05
+	}
+
+	if c.Adaptive.LatencyThreshold < 0 {
+		c.Adaptive.LatencyThreshold = 0
+	}
+
+	if c.Fetch.Workers < 1 {
+		c.Fetch.Workers = 8
+	}
+
+	if c.Fetch.RateLimit < 0 {
+		return fmt.Errorf("fetch.rate_limit_per_second must not be negative")
+	}
+
+	if c.Fetch.Burst < 1 {
+		c.Fetch.Burst = 1
+	}
+
+	if c.Retry.MaxAttempts < 1 {
+		c.Retry.MaxAttempts = 1
+	}
+
+	if c.Retry.BaseDelay <= 0 {
+		c.Retry.BaseDelay = time.Second
+	}
+
+	if c.Retry.MaxDelay < c.Retry.BaseDelay {
+		c.Retry.MaxDelay = c.Retry.BaseDelay
+	}
+
+	if c.Chunking.Enabled && c.ChunkBudget() < 1000 {
+		return fmt.Errorf("chunk budget for %s must be at least 1000", c.ModelID())
+	}
+
+	if c.Chunking.MaxChunks < 1 {
+		c.Chunking.MaxChunks = 8
+	}
+
+	if c.Server.MaxBodyBytes <= 0 {
+		c.Server.MaxBodyBytes = 1 << 20
+	}
+
+	if c.Server.MaxURLs < 1 {
+		c.Server.MaxURLs = 100
+	}
+
+	if c.Server.QueueSize < 1 {
+		c.Server.QueueSize = 16
+	}
+
+	if c.Server.JobTTL <= 0 {
+		c.Server.JobTTL = time.Hour
+	}
+
+	switch c.Watch.Format {
+	case "markdown", "json", "html":
+	default:
+		return fmt.Errorf("invalid watch.format: %s (must be 'markdown', 'json' or 'html')", c.Watch.Format)
+	}
+
+	switch c.Watch.GroupBy {
+	case "", "category", "project", "interest", "source":
+	default:
+		return fmt.Errorf("invalid watch.group_by: %s (must be 'category', 'project', 'interest' or 'source')", c.Watch.GroupBy)
+	}
+
+	for i, sink := range c.Notify.Sinks {
+		switch sink.Type {
+		case "slack", "discord", "webhook":
+			if sink.URL == "" {
+				return fmt.Errorf("notify.sinks[%d].url must be specified", i)
+			}
+		case "email":
+			if sink.SMTP.Host == "" {
+				return fmt.Errorf("notify.sinks[%d].smtp.host must be specified", i)
+			}
+		default:
+			return fmt.Errorf("invalid notify.sinks[%d].type: %s (must be 'slack', 'discord', 'webhook' or 'email')", i, sink.Type)
+		}
+	}
+
+	if c.Notify.MaxAttempts < 1 {
+		c.Notify.MaxAttempts = 1
+	}
+
+	if c.Notify.Timeout <= 0 {
+		c.Notify.Timeout = 10 * time.Second
+	}
+
+	if c.Politeness.HostConcurrency < 1 {
+		c.Politeness.HostConcurrency = 2
+	}
+
+	if c.Politeness.HostInterval < 0 {
+		c.Politeness.HostInterval = 0
+	}
+
+	if c.Politeness.MaxCrawlDelay <= 0 {
+		c.Politeness.MaxCrawlDelay = 30 * time.Second
+	}
+
+	if c.Watch.RetryDelay <= 0 {
+		c.Watch.RetryDelay = time.Minute
+	}
+
+	if c.Watch.MaxRetryDelay < c.Watch.RetryDelay {
+		c.Watch.MaxRetryDelay = c.Watch.RetryDelay
+	}
+
+	return nil
+}
+
+// ModelID identifies the provider configuration (primary plus fallbacks),
+// e.g. "openai/gpt-4o-mini,anthropic/claude-haiku-4-5".
+func (c *Config) ModelID() string {
+	ids := []string{string(c.LLMProvider) + "/" + c.Model}
+	for _, fb := range c.Fallback {
+		ids = append(ids, string(fb.Provider)+"/"+fb.Model)
+	}
+	return strings.Join(ids, ",")
+}
+
+// validateProvider checks that p is known and has the credentials it needs.
+func (c *Config) validateProvider(field string, p LLMProvider) error {
+	switch p {
+	case ProviderOpenAI, ProviderOllama, ProviderAnthropic:
+	default:
+		return fmt.Errorf("invalid %s: %s (must be 'openai', 'ollama' or 'anthropic')", field, p)
+	}
+
+	// Self-hosted compatible endpoints may not require a key
+	if p == ProviderOpenAI && c.APIKey == "" && c.OpenAI.BaseURL == "" {
+		return fmt.Errorf("api_key is required for OpenAI provider")
+	}
+
+	if p == ProviderAnthropic && c.AnthropicAPIKey == "" {
+		return fmt.Errorf("anthropic_api_key is required for Anthropic provider")
+	}
+
 	return nil
 }
 
@@ -144,3 +627,25 @@ func (c *Config) InterestsString() string {
 	}
 	return result
 }
+
+// ChunkBudget returns the per-chunk size for the smallest model in the
+// provider chain, so chunks still fit after falling back. Each model uses
+// its own override, then its provider's, then chunking.chunk_chars.
+func (c *Config) ChunkBudget() int {
+	budget := c.chunkBudget(c.LLMProvider, c.Model)
+	for _, fb := range c.Fallback {
+		budget = min(budget, c.chunkBudget(fb.Provider, fb.Model))
+	}
+	return budget
+}
+
+// chunkBudget returns the per-chunk size for one provider and model.
+func (c *Config) chunkBudget(provider LLMProvider, model string) int {
+	if n, ok := c.Chunking.Budgets[model]; ok && n > 0 {
+		return n
+	}
+	if n, ok := c.Chunking.Budgets[string(provider)]; ok && n > 0 {
+		return n
+	}
+	return c.Chunking.ChunkChars
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback FallbackConfig
+		wantErr  string
+	}{
+		{
+			name:     "valid",
+			fallback: FallbackConfig{Provider: ProviderOllama, Model: "llama3.1"},
+		},
+		{
+			name:     "unknown provider",
+			fallback: FallbackConfig{Provider: "gemini", Model: "gemini-pro"},
+			wantErr:  "invalid fallback[0].provider",
+		},
+		{
+			name:     "missing model",
+			fallback: FallbackConfig{Provider: ProviderOllama},
+			wantErr:  "fallback[0].model must be specified",
+		},
+		{
+			name:     "missing anthropic key",
+			fallback: FallbackConfig{Provider: ProviderAnthropic, Model: "claude-haiku-4-5"},
+			wantErr:  "anthropic_api_key is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.APIKey = "sk-test"
+			cfg.Fallback = []FallbackConfig{tt.fallback}
+
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChunkBudget(t *testing.T) {
+	tests := []struct {
+		name     string
+		budgets  map[string]int
+		fallback []FallbackConfig
+		want     int
+	}{
+		{name: "default", want: 15000},
+		{name: "model override", budgets: map[string]int{"gpt-4o-mini": 60000}, want: 60000},
+		{
+			name:     "smaller fallback model",
+			budgets:  map[string]int{"gpt-4o-mini": 60000, "llama3": 6000},
+			fallback: []FallbackConfig{{Provider: ProviderOllama, Model: "llama3"}},
+			want:     6000,
+		},
+		{
+			name:     "fallback provider override",
+			budgets:  map[string]int{"gpt-4o-mini": 60000, "ollama": 8000},
+			fallback: []FallbackConfig{{Provider: ProviderOllama, Model: "llama3"}},
+			want:     8000,
+		},
+		{
+			name:     "fallback without override",
+			budgets:  map[string]int{"gpt-4o-mini": 60000},
+			fallback: []FallbackConfig{{Provider: ProviderOllama, Model: "llama3"}},
+			want:     15000,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Model = "gpt-4o-mini"
+			cfg.Chunking.Budgets = tt.budgets
+			cfg.Fallback = tt.fallback
+
+			if got := cfg.ChunkBudget(); got != tt.want {
+				t.Errorf("ChunkBudget() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,11 +3,19 @@ package fetcher
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/netip"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	readability "github.com/go-shiori/go-readability"
 )
@@ -18,33 +26,189 @@ type Article struct {
 	Title   string
 	Content string
 	Excerpt string
+
+	// NotModified is set when the server answered 304 and the article was
+	// reused from the PageStore.
+	NotModified bool `json:"-"`
+}
+
+// Page is the last successful response for a URL: its HTTP cache validators
+// and the article extracted from it.
+type Page struct {
+	ETag         string
+	LastModified string
+	Article      *Article
+}
+
+// PageStore persists pages between runs so Fetch can send conditional requests.
+type PageStore interface {
+	GetPage(url string) (*Page, bool)
+	PutPage(url string, page *Page) error
+}
+
+// ContentHash returns a hex SHA-256 of the extracted content, used to detect
+// whether an article changed between runs.
+func (a *Article) ContentHash() string {
+	sum := sha256.Sum256([]byte(a.Content))
+	return hex.EncodeToString(sum[:])
 }
 
 // Fetcher handles HTTP requests and content extraction.
 type Fetcher struct {
-	client  *http.Client
-	timeout time.Duration
-}
-
-// New creates a new Fetcher with sensible defaults.
-func New() *Fetcher {
-	return &Fetcher{
-		client: &http.Client{
-			Timeout: 30 * time.Second,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) >= 10 {
-					return fmt.Errorf("too many redirects")
-				}
-				return nil
-			},
-		},
-		timeout: 30 * time.Second,
+	client   *http.Client
+	timeout  time.Duration
+	maxChars int
+	pages    PageStore
+	policy   *Policy
+
+	respectRobots bool
+	maxCrawlDelay time.Duration
+	robots        *robotsCache
+	hosts         *hostLimiter
+}
+
+// UserAgent identifies smart-digest in outgoing HTTP requests.
+const UserAgent = "Mozilla/5.0 (compatible; SmartDigest/1.0; +https://github.com/taro33333/smart-digest)"
+
+// Politeness defaults used when none are configured.
+const (
+	DefaultHostConcurrency = 2
+	DefaultHostInterval    = time.Second
+	DefaultMaxCrawlDelay   = 30 * time.Second
+)
+
+// DefaultMaxChars is the content size limit used when none is configured,
+// chosen to fit a single LLM request.
+const DefaultMaxChars = 15000
+
+// Option customizes optional Fetcher behaviour.
+type Option func(*Fetcher)
+
+// WithMaxChars sets the size in bytes at which extracted content is truncated.
+// Raise it when long articles are summarized in chunks.
+func WithMaxChars(n int) Option {
+	return func(f *Fetcher) {
+		if n > 0 {
+			f.maxChars = n
+		}
 	}
 }
 
-// Fetch retrieves and extracts clean content from a URL.
-func (f *Fetcher) Fetch(ctx context.Context, targetURL string) (*Article, error) {
-	// Validate URL
+// WithPageStore enables conditional requests (If-None-Match / If-Modified-Since)
+// using validators remembered in store.
+func WithPageStore(store PageStore) Option {
+	return func(f *Fetcher) {
+		f.pages = store
+	}
+}
+
+// WithPolicy restricts the destinations articles may be fetched from.
+// A nil policy disables the checks, including the default block of private
+// and link-local addresses.
+func WithPolicy(p *Policy) Option {
+	return func(f *Fetcher) {
+		f.policy = p
+	}
+}
+
+// WithRobots sets whether robots.txt is honored. Crawl-delay values above
+// maxCrawlDelay are capped; zero keeps the default.
+func WithRobots(respect bool, maxCrawlDelay time.Duration) Option {
+	return func(f *Fetcher) {
+		f.respectRobots = respect
+		if maxCrawlDelay > 0 {
+			f.maxCrawlDelay = maxCrawlDelay
+		}
+	}
+}
+
+// WithHostLimits sets how many requests may run against one host at a time
+// and the minimum time between their starts. A longer Crawl-delay from
+// robots.txt takes precedence over interval.
+func WithHostLimits(concurrency int, interval time.Duration) Option {
+	return func(f *Fetcher) {
+		f.hosts = newHostLimiter(concurrency, interval)
+	}
+}
+
+// New creates a new Fetcher with sensible defaults. Unless WithPolicy says
+// otherwise, DefaultPolicy applies; robots.txt is honored and requests per
+// host are limited to the Default* politeness settings.
+func New(opts ...Option) *Fetcher {
+	f := &Fetcher{
+		timeout:       30 * time.Second,
+		maxChars:      DefaultMaxChars,
+		policy:        DefaultPolicy(),
+		respectRobots: true,
+		maxCrawlDelay: DefaultMaxCrawlDelay,
+		hosts:         newHostLimiter(DefaultHostConcurrency, DefaultHostInterval),
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	checkRedirect := func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("too many redirects")
+		}
+		if f.policy != nil {
+			return f.policy.CheckHost(req.URL.Host, defaultPort(req.URL.Scheme))
+		}
+		return nil
+	}
+	f.client = &http.Client{
+		Timeout:       f.timeout,
+		CheckRedirect: checkRedirect,
+	}
+	if f.policy != nil {
+		f.client.Transport = f.policy.transport()
+	}
+
+	if f.respectRobots {
+		// robots.txt itself is fetched without the robots check on redirects
+		robotsClient := *f.client
+		f.robots = newRobotsCache(&robotsClient)
+		f.client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			if err := checkRedirect(req, via); err != nil {
+				return err
+			}
+			_, err := f.robotsDelay(req.Context(), req.URL)
+			return err
+		}
+	}
+
+	return f
+}
+
+// robotsDelay checks u against robots.txt and returns the host's capped
+// Crawl-delay. The error wraps ErrDisallowed when u may not be fetched.
+func (f *Fetcher) robotsDelay(ctx context.Context, u *url.URL) (time.Duration, error) {
+	if f.robots == nil {
+		return 0, nil
+	}
+	rules, err := f.robots.rules(ctx, u)
+	if err != nil {
+		return 0, err
+	}
+	if rules.unreachable != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDisallowed, rules.unreachable)
+	}
+	if !rules.allowed(robotsPath(u)) {
+		return 0, fmt.Errorf("%w: %s", ErrDisallowed, robotsPath(u))
+	}
+	return min(rules.crawlDelay, f.maxCrawlDelay), nil
+}
+
+// CheckURL validates targetURL against the scheme and the network policy
+// without connecting. Host names are not resolved, so only IP literals are
+// checked against the address rules; Fetch checks resolved addresses too.
+func (f *Fetcher) CheckURL(targetURL string) error {
+	_, err := f.checkURL(targetURL)
+	return err
+}
+
+// checkURL implements CheckURL and returns the parsed URL.
+func (f *Fetcher) checkURL(targetURL string) (*url.URL, error) {
 	parsedURL, err := url.Parse(targetURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid URL %s: %w", targetURL, err)
@@ -54,31 +218,67 @@ func (f *Fetcher) Fetch(ctx context.Context, targetURL string) (*Article, error)
 		return nil, fmt.Errorf("unsupported URL scheme: %s", parsedURL.Scheme)
 	}
 
-	// Create request with context
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if f.policy == nil {
+		return parsedURL, nil
+	}
+
+	port := parsedURL.Port()
+	if port == "" {
+		port = defaultPort(parsedURL.Scheme)
+	}
+	err = f.policy.CheckHost(parsedURL.Host, port)
+	if ip, perr := netip.ParseAddr(parsedURL.Hostname()); err == nil && perr == nil {
+		if n, perr := strconv.ParseUint(port, 10, 16); perr == nil {
+			err = f.policy.CheckAddr(parsedURL.Hostname(), netip.AddrPortFrom(ip, uint16(n)))
+		}
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("refused to fetch URL %s: %w", targetURL, err)
 	}
+	return parsedURL, nil
+}
 
-	// Set User-Agent to avoid being blocked
-	req.Header.Set("User-Agent", "Mozilla/5.0 (compatible; SmartDigest/1.0; +https://github.com/taro33333/smart-digest)")
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.5,ja;q=0.3")
+// Fetch retrieves and extracts clean content from a URL.
+func (f *Fetcher) Fetch(ctx context.Context, targetURL string) (*Article, error) {
+	header := http.Header{}
+	header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+	header.Set("Accept-Language", "en-US,en;q=0.5,ja;q=0.3")
+
+	// Revalidate the previous response instead of downloading it again
+	var previous *Page
+	if f.pages != nil {
+		if page, ok := f.pages.GetPage(targetURL); ok && page.Article != nil {
+			previous = page
+			if page.ETag != "" {
+				header.Set("If-None-Match", page.ETag)
+			}
+			if page.LastModified != "" {
+				header.Set("If-Modified-Since", page.LastModified)
+			}
+		}
+	}
 
 	// Execute request
-	resp, err := f.client.Do(req)
+	resp, err := f.Get(ctx, targetURL, header)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch URL %s: %w", targetURL, err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified && previous != nil {
+		article := *previous.Article
+		article.URL = targetURL
+		article.NotModified = true
+		return &article, nil
+	}
+
 	// Check status code
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("HTTP %d for URL %s", resp.StatusCode, targetURL)
 	}
 
 	// Parse with readability
-	article, err := readability.FromReader(resp.Body, parsedURL)
+	article, err := readability.FromReader(resp.Body, resp.Request.URL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse content from %s: %w", targetURL, err)
 	}
@@ -91,17 +291,117 @@ func (f *Fetcher) Fetch(ctx context.Context, targetURL string) (*Article, error)
 	}
 
 	// Truncate if too long (LLM context limit consideration)
-	const maxChars = 15000
-	if len(content) > maxChars {
-		content = content[:maxChars] + "\n...[truncated]"
+	if len(content) > f.maxChars {
+		content = truncateUTF8(content, f.maxChars) + "\n...[truncated]"
 	}
 
-	return &Article{
+	result := &Article{
 		URL:     targetURL,
 		Title:   article.Title,
 		Content: content,
 		Excerpt: truncateString(article.Excerpt, 300),
-	}, nil
+	}
+
+	if f.pages != nil {
+		etag := resp.Header.Get("ETag")
+		lastModified := resp.Header.Get("Last-Modified")
+		if etag != "" || lastModified != "" {
+			// Remembering validators is best-effort; a failed write only
+			// means the next run does a full GET
+			_ = f.pages.PutPage(targetURL, &Page{
+				ETag:         etag,
+				LastModified: lastModified,
+				Article:      result,
+			})
+		}
+	}
+
+	return result, nil
+}
+
+// Get sends a GET request for targetURL with header, under the same scheme,
+// network policy, robots.txt and per-host checks as Fetch. The caller must
+// close the response body, which also frees the request's host slot.
+func (f *Fetcher) Get(ctx context.Context, targetURL string, header http.Header) (*http.Response, error) {
+	parsedURL, err := f.checkURL(targetURL)
+	if err != nil {
+		return nil, err
+	}
+
+	crawlDelay, err := f.robotsDelay(ctx, parsedURL)
+	if err != nil {
+		return nil, fmt.Errorf("refused to fetch URL %s: %w", targetURL, err)
+	}
+
+	release, err := f.hosts.acquire(ctx, parsedURL.Host, crawlDelay)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		release()
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	// Set User-Agent to avoid being blocked
+	if req.Header.Get("User-Agent") == "" {
+		req.Header.Set("User-Agent", UserAgent)
+	}
+
+	resp, err := f.do(req)
+	if err != nil {
+		release()
+		return nil, err
+	}
+	resp.Body = &releaseBody{ReadCloser: resp.Body, release: release}
+	return resp, nil
+}
+
+// releaseBody frees a host slot when the response body is closed.
+type releaseBody struct {
+	io.ReadCloser
+	release func()
+	once    sync.Once
+}
+
+// Close closes the body and frees the host slot once.
+func (b *releaseBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// do executes req with the policy-aware client.
+func (f *Fetcher) do(req *http.Request) (*http.Response, error) {
+	targetURL := req.URL.String()
+	resp, err := f.client.Do(req)
+	if err != nil {
+		// Report a blocked connection without the transport noise, naming
+		// the host that was dialed (which may be a redirect target)
+		var blocked *BlockedError
+		if errors.As(err, &blocked) {
+			var urlErr *url.Error
+			if blocked.Host == "" && errors.As(err, &urlErr) {
+				if u, perr := url.Parse(urlErr.URL); perr == nil {
+					blocked.Host = u.Hostname()
+				}
+			}
+			return nil, fmt.Errorf("refused to fetch URL %s: %w", targetURL, blocked)
+		}
+		return nil, fmt.Errorf("failed to fetch URL %s: %w", targetURL, err)
+	}
+	return resp, nil
+}
+
+// defaultPort returns the port implied by an http or https URL scheme.
+func defaultPort(scheme string) string {
+	if scheme == "https" {
+		return "443"
+	}
+	return "80"
 }
 
 // cleanText removes excessive whitespace and normalizes line breaks.
@@ -132,5 +432,16 @@ func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen-3] + "..."
+	return truncateUTF8(s, maxLen-3) + "..."
+}
+
+// truncateUTF8 cuts s to at most n bytes without splitting a multi-byte character.
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
 }
@@ -0,0 +1,75 @@
+package fetcher
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hostLimiter bounds the number of concurrent requests to each host and
+// spaces out their start times.
+type hostLimiter struct {
+	concurrency int
+	interval    time.Duration
+
+	mu    sync.Mutex
+	hosts map[string]*hostState
+}
+
+type hostState struct {
+	slots chan struct{}
+
+	mu   sync.Mutex
+	next time.Time // earliest start of the next request
+}
+
+func newHostLimiter(concurrency int, interval time.Duration) *hostLimiter {
+	return &hostLimiter{
+		concurrency: max(concurrency, 1),
+		interval:    max(interval, 0),
+		hosts:       make(map[string]*hostState),
+	}
+}
+
+// acquire waits for a free slot on host and for its next start time. The
+// interval is the larger of the configured one and delay (the host's
+// Crawl-delay). The returned func releases the slot.
+func (l *hostLimiter) acquire(ctx context.Context, host string, delay time.Duration) (func(), error) {
+	l.mu.Lock()
+	key := strings.ToLower(host)
+	s, ok := l.hosts[key]
+	if !ok {
+		s = &hostState{slots: make(chan struct{}, l.concurrency)}
+		l.hosts[key] = s
+	}
+	l.mu.Unlock()
+
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	release := func() { <-s.slots }
+
+	// Reserve a start time so concurrent requests queue up behind each other
+	s.mu.Lock()
+	start := time.Now()
+	if s.next.After(start) {
+		start = s.next
+	}
+	s.next = start.Add(max(l.interval, delay))
+	s.mu.Unlock()
+
+	if wait := time.Until(start); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			release()
+			return nil, ctx.Err()
+		}
+	}
+	return release, nil
+}
@@ -0,0 +1,319 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrBlocked is matched by errors.Is for any destination rejected by a Policy.
+var ErrBlocked = errors.New("blocked by network policy")
+
+// BlockedError reports a destination rejected by the network policy.
+type BlockedError struct {
+	Host   string // host from the URL, empty if unknown
+	Addr   string // resolved "ip:port", empty when rejected before dialing
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *BlockedError) Error() string {
+	target := e.Host
+	switch {
+	case target == "":
+		target = e.Addr
+	case e.Addr != "":
+		target += " (" + e.Addr + ")"
+	}
+	return fmt.Sprintf("%v: %s: %s", ErrBlocked, target, e.Reason)
+}
+
+// Is makes errors.Is(err, ErrBlocked) match.
+func (e *BlockedError) Is(target error) bool {
+	return target == ErrBlocked
+}
+
+// reservedRanges are blocked unless exempted by Rules.AllowCIDRs: loopback,
+// private, link-local (including cloud metadata endpoints), carrier-grade
+// NAT, benchmarking, unspecified, multicast and NAT64 addresses.
+var reservedRanges = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("10.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("127.0.0.0/8"),
+	netip.MustParsePrefix("169.254.0.0/16"),
+	netip.MustParsePrefix("172.16.0.0/12"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.168.0.0/16"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("224.0.0.0/4"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("::/128"),
+	netip.MustParsePrefix("::1/128"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("fc00::/7"),
+	netip.MustParsePrefix("fe80::/10"),
+	netip.MustParsePrefix("ff00::/8"),
+}
+
+// Rules are the user-configured allow and deny lists of a Policy.
+type Rules struct {
+	AllowHosts []string // when set, only these hosts may be fetched ("example.com", "*.example.com")
+	DenyHosts  []string // never fetched
+	AllowCIDRs []string // exempt from the reserved-range block
+	DenyCIDRs  []string // always blocked, even if listed in AllowCIDRs
+	AllowPorts []int    // when set, only these ports may be used
+	DenyPorts  []int    // never used
+}
+
+// Policy decides which destinations the fetcher may connect to. Host and
+// port rules are checked for the request and every redirect; address rules
+// are checked on the resolved IP of every connection, so host names that
+// resolve to internal addresses are caught as well. Behind a proxy the
+// destination is resolved and checked before the request is handed over.
+type Policy struct {
+	allowHosts []string
+	denyHosts  []string
+	allowCIDRs []netip.Prefix
+	denyCIDRs  []netip.Prefix
+	allowPorts []int
+	denyPorts  []int
+}
+
+// DefaultPolicy blocks reserved destinations such as private, loopback and
+// link-local addresses and allows everything else.
+func DefaultPolicy() *Policy {
+	return &Policy{}
+}
+
+// NewPolicy builds a policy from r on top of the reserved-range block.
+func NewPolicy(r Rules) (*Policy, error) {
+	p := &Policy{
+		allowPorts: r.AllowPorts,
+		denyPorts:  r.DenyPorts,
+	}
+	for _, h := range r.AllowHosts {
+		p.allowHosts = append(p.allowHosts, normalizeHost(h))
+	}
+	for _, h := range r.DenyHosts {
+		p.denyHosts = append(p.denyHosts, normalizeHost(h))
+	}
+
+	var err error
+	if p.allowCIDRs, err = parsePrefixes(r.AllowCIDRs); err != nil {
+		return nil, err
+	}
+	if p.denyCIDRs, err = parsePrefixes(r.DenyCIDRs); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// CheckHost applies the host and port rules to a URL host ("name" or
+// "name:port"). defaultPort is used when host has no port.
+func (p *Policy) CheckHost(host, defaultPort string) error {
+	name, port := host, defaultPort
+	if h, pt, err := net.SplitHostPort(host); err == nil {
+		name, port = h, pt
+	}
+	name = normalizeHost(name)
+
+	if slices.ContainsFunc(p.denyHosts, func(pattern string) bool { return matchHost(pattern, name) }) {
+		return &BlockedError{Host: name, Reason: "host is in deny_hosts"}
+	}
+	if len(p.allowHosts) > 0 && !slices.ContainsFunc(p.allowHosts, func(pattern string) bool { return matchHost(pattern, name) }) {
+		return &BlockedError{Host: name, Reason: "host is not in allow_hosts"}
+	}
+	if n, err := strconv.Atoi(port); err == nil {
+		if reason := p.portDenied(n); reason != "" {
+			return &BlockedError{Host: name, Reason: reason}
+		}
+	}
+	return nil
+}
+
+// CheckAddr applies the address and port rules to a resolved IP and port.
+// host is only used in the error.
+func (p *Policy) CheckAddr(host string, addr netip.AddrPort) error {
+	// Zoned addresses never match a prefix, so compare without the zone
+	ip := addr.Addr().Unmap().WithZone("")
+
+	if reason := p.portDenied(int(addr.Port())); reason != "" {
+		return &BlockedError{Host: host, Addr: addr.String(), Reason: reason}
+	}
+	if containsAddr(p.denyCIDRs, ip) {
+		return &BlockedError{Host: host, Addr: addr.String(), Reason: "address is in deny_cidrs"}
+	}
+	if containsAddr(p.allowCIDRs, ip) {
+		return nil
+	}
+	if containsAddr(reservedRanges, ip) {
+		return &BlockedError{Host: host, Addr: addr.String(), Reason: "private or reserved address (add it to allow_cidrs to permit)"}
+	}
+	return nil
+}
+
+// portDenied returns why port is rejected, or "" when it is allowed.
+func (p *Policy) portDenied(port int) string {
+	if slices.Contains(p.denyPorts, port) {
+		return fmt.Sprintf("port %d is in deny_ports", port)
+	}
+	if len(p.allowPorts) > 0 && !slices.Contains(p.allowPorts, port) {
+		return fmt.Sprintf("port %d is not in allow_ports", port)
+	}
+	return ""
+}
+
+// control is a net.Dialer Control hook that rejects connections to
+// addresses the policy blocks. It runs after DNS resolution, for every
+// connection including redirects.
+func (p *Policy) control(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return &BlockedError{Addr: address, Reason: "unparsable address"}
+	}
+	return p.CheckAddr("", addr)
+}
+
+// proxyFromEnvironment picks the proxy for a request; replaced in tests.
+var proxyFromEnvironment = http.ProxyFromEnvironment
+
+// transport returns an HTTP transport that enforces the policy. Direct
+// connections are checked on their resolved address. Requests sent through
+// HTTP_PROXY/HTTPS_PROXY are checked by resolving the destination locally
+// first, since the proxy connects on our behalf; the proxy itself is
+// configured by the operator and is dialed without the address check.
+func (p *Policy) transport() *http.Transport {
+	direct := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   p.control,
+	}
+	trusted := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	var proxies sync.Map // "host:port" of proxies in use
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(req *http.Request) (*url.URL, error) {
+		proxy, err := proxyFromEnvironment(req)
+		if err != nil || proxy == nil {
+			return proxy, err
+		}
+		if err := p.checkResolved(req.Context(), req.URL); err != nil {
+			return nil, err
+		}
+		proxies.Store(proxyAddr(proxy), true)
+		return proxy, nil
+	}
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if _, ok := proxies.Load(addr); ok {
+			return trusted.DialContext(ctx, network, addr)
+		}
+		return direct.DialContext(ctx, network, addr)
+	}
+	return transport
+}
+
+// checkResolved applies the address rules to the addresses u's host
+// resolves to. A host that does not resolve locally is left to the proxy,
+// as is common where only the proxy can reach external DNS.
+func (p *Policy) checkResolved(ctx context.Context, u *url.URL) error {
+	host := u.Hostname()
+	port := u.Port()
+	if port == "" {
+		port = defaultPort(u.Scheme)
+	}
+	n, err := strconv.ParseUint(port, 10, 16)
+	if err != nil {
+		return &BlockedError{Host: host, Reason: "invalid port"}
+	}
+
+	var addrs []netip.Addr
+	if ip, err := netip.ParseAddr(host); err == nil {
+		addrs = []netip.Addr{ip}
+	} else if addrs, err = net.DefaultResolver.LookupNetIP(ctx, "ip", host); err != nil {
+		return nil
+	}
+	for _, ip := range addrs {
+		if err := p.CheckAddr(host, netip.AddrPortFrom(ip, uint16(n))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// proxyAddr returns the "host:port" a proxy URL is dialed at.
+func proxyAddr(proxy *url.URL) string {
+	port := proxy.Port()
+	if port == "" {
+		switch proxy.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		default:
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(proxy.Hostname(), port)
+}
+
+// normalizeHost lowercases a host name and strips brackets and the trailing dot.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	host = strings.TrimSuffix(strings.TrimPrefix(host, "["), "]")
+	return strings.TrimSuffix(host, ".")
+}
+
+// matchHost reports whether name matches pattern, where "*.example.com"
+// matches any subdomain of example.com but not example.com itself.
+func matchHost(pattern, name string) bool {
+	if suffix, ok := strings.CutPrefix(pattern, "*"); ok {
+		return strings.HasSuffix(name, suffix) && len(name) > len(suffix)
+	}
+	return pattern == name
+}
+
+// parsePrefixes parses CIDRs; a bare IP is treated as a single address.
+func parsePrefixes(list []string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, s := range list {
+		s = strings.TrimSpace(s)
+		if !strings.Contains(s, "/") {
+			addr, err := netip.ParseAddr(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid CIDR %q: %w", s, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR %q: %w", s, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// containsAddr reports whether any prefix contains ip.
+func containsAddr(prefixes []netip.Prefix, ip netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCheckAddr(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   Rules
+		addr    string
+		blocked bool
+	}{
+		{name: "public IPv4", addr: "93.184.216.34:443"},
+		{name: "public IPv6", addr: "[2606:2800:220:1::1]:443"},
+		{name: "loopback", addr: "127.0.0.1:80", blocked: true},
+		{name: "private 10/8", addr: "10.1.2.3:80", blocked: true},
+		{name: "private 172.16/12", addr: "172.31.255.1:80", blocked: true},
+		{name: "private 192.168/16", addr: "192.168.0.1:80", blocked: true},
+		{name: "cloud metadata", addr: "169.254.169.254:80", blocked: true},
+		{name: "carrier-grade NAT", addr: "100.64.0.1:80", blocked: true},
+		{name: "unspecified", addr: "0.0.0.0:80", blocked: true},
+		{name: "IPv6 loopback", addr: "[::1]:80", blocked: true},
+		{name: "IPv6 unique local", addr: "[fd00::1]:80", blocked: true},
+		{name: "IPv6 link-local with zone", addr: "[fe80::1%eth0]:80", blocked: true},
+		{name: "IPv4-mapped loopback", addr: "[::ffff:127.0.0.1]:80", blocked: true},
+		{name: "IPv4-mapped metadata", addr: "[::ffff:169.254.169.254]:80", blocked: true},
+		{name: "NAT64 prefix", addr: "[64:ff9b::a9fe:a9fe]:80", blocked: true},
+		{
+			name:  "allowed private range",
+			rules: Rules{AllowCIDRs: []string{"10.20.0.0/16"}},
+			addr:  "10.20.1.1:80",
+		},
+		{
+			name:  "allowed single address as IPv4-mapped",
+			rules: Rules{AllowCIDRs: []string{"127.0.0.1"}},
+			addr:  "[::ffff:127.0.0.1]:80",
+		},
+		{
+			name:    "deny overrides allow",
+			rules:   Rules{AllowCIDRs: []string{"10.0.0.0/8"}, DenyCIDRs: []string{"10.0.0.5"}},
+			addr:    "10.0.0.5:80",
+			blocked: true,
+		},
+		{
+			name:    "denied public range",
+			rules:   Rules{DenyCIDRs: []string{"93.184.216.0/24"}},
+			addr:    "93.184.216.34:443",
+			blocked: true,
+		},
+		{
+			name:    "port not allowed",
+			rules:   Rules{AllowPorts: []int{80, 443}},
+			addr:    "93.184.216.34:8080",
+			blocked: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPolicy(tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.CheckAddr("", netip.MustParseAddrPort(tt.addr))
+			if got := errors.Is(err, ErrBlocked); got != tt.blocked {
+				t.Errorf("CheckAddr(%s) = %v, blocked %v", tt.addr, err, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   Rules
+		host    string
+		blocked bool
+	}{
+		{name: "no rules", host: "example.com"},
+		{name: "denied exact", rules: Rules{DenyHosts: []string{"evil.test"}}, host: "evil.test", blocked: true},
+		{name: "denied case and trailing dot", rules: Rules{DenyHosts: []string{"Evil.Test"}}, host: "EVIL.test.", blocked: true},
+		{name: "denied subdomain", rules: Rules{DenyHosts: []string{"*.evil.test"}}, host: "a.b.evil.test", blocked: true},
+		{name: "glob excludes apex", rules: Rules{DenyHosts: []string{"*.evil.test"}}, host: "evil.test"},
+		{name: "glob needs a dot boundary", rules: Rules{DenyHosts: []string{"*.evil.test"}}, host: "notevil.test"},
+		{name: "allowed subdomain", rules: Rules{AllowHosts: []string{"*.example.com"}}, host: "blog.example.com"},
+		{name: "not in allow list", rules: Rules{AllowHosts: []string{"*.example.com"}}, host: "example.org", blocked: true},
+		{
+			name:    "deny wins over allow",
+			rules:   Rules{AllowHosts: []string{"*.example.com"}, DenyHosts: []string{"internal.example.com"}},
+			host:    "internal.example.com",
+			blocked: true,
+		},
+		{name: "bracketed IPv6", rules: Rules{DenyHosts: []string{"::1"}}, host: "[::1]:8080", blocked: true},
+		{name: "denied port", rules: Rules{DenyPorts: []int{22}}, host: "example.com:22", blocked: true},
+		{name: "default port", rules: Rules{AllowPorts: []int{443}}, host: "example.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPolicy(tt.rules)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = p.CheckHost(tt.host, "443")
+			if got := errors.Is(err, ErrBlocked); got != tt.blocked {
+				t.Errorf("CheckHost(%s) = %v, blocked %v", tt.host, err, tt.blocked)
+			}
+		})
+	}
+}
+
+func TestFetchRedirectToPrivateAddress(t *testing.T) {
+	tests := []struct {
+		name   string
+		target string
+	}{
+		{name: "metadata address", target: "http://169.254.169.254/latest/meta-data/"},
+		{name: "denied host", target: "http://internal.example.com/"},
+		{name: "loopback on another port", target: "http://127.0.0.2:9/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.RedirectHandler(tt.target, http.StatusFound))
+			defer srv.Close()
+
+			// The test server itself is on loopback, so allow exactly its address
+			policy, err := NewPolicy(Rules{
+				AllowCIDRs: []string{"127.0.0.1"},
+				DenyHosts:  []string{"*.example.com"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			f := New(WithPolicy(policy), WithRobots(false, 0))
+
+			_, err = f.Fetch(context.Background(), srv.URL)
+			if !errors.Is(err, ErrBlocked) {
+				t.Fatalf("Fetch() = %v, want ErrBlocked", err)
+			}
+		})
+	}
+}
+
+func TestFetchThroughProxy(t *testing.T) {
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = append(proxied, r.URL.String())
+		fmt.Fprintf(w, "<html><body><p>%s</p></body></html>", strings.Repeat("proxied article ", 20))
+	}))
+	defer proxy.Close()
+
+	proxyURL, _ := url.Parse(proxy.URL)
+	original := proxyFromEnvironment
+	proxyFromEnvironment = func(*http.Request) (*url.URL, error) { return proxyURL, nil }
+	defer func() { proxyFromEnvironment = original }()
+
+	// The proxy is on loopback but is trusted; destinations are still checked
+	f := New(WithRobots(false, 0))
+
+	if _, err := f.Fetch(context.Background(), "http://93.184.216.34/article"); err != nil {
+		t.Fatalf("Fetch() through proxy = %v, want success", err)
+	}
+	if len(proxied) != 1 {
+		t.Fatalf("proxied requests = %v, want 1", proxied)
+	}
+
+	for _, target := range []string{"http://10.1.2.3/", "http://[::ffff:169.254.169.254]/"} {
+		if _, err := f.Fetch(context.Background(), target); !errors.Is(err, ErrBlocked) {
+			t.Errorf("Fetch(%s) through proxy = %v, want ErrBlocked", target, err)
+		}
+	}
+	if len(proxied) != 1 {
+		t.Errorf("proxied requests = %v, want blocked requests not forwarded", proxied)
+	}
+}
@@ -0,0 +1,274 @@
+package fetcher
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrDisallowed is matched by errors.Is for URLs refused because of robots.txt.
+var ErrDisallowed = errors.New("disallowed by robots.txt")
+
+// robotsAgent is the product token matched against robots.txt User-agent lines.
+const robotsAgent = "smartdigest"
+
+const (
+	// robotsTTL is how long a fetched robots.txt is reused.
+	robotsTTL = 24 * time.Hour
+	// robotsErrorTTL is how long an unreachable robots.txt blocks its host
+	// before it is tried again.
+	robotsErrorTTL = 10 * time.Minute
+	// robotsMaxBytes is the most of a robots.txt that is parsed (RFC 9309
+	// requires at least 500 KiB).
+	robotsMaxBytes = 512 << 10
+)
+
+// robotsRules are the rules of the robots.txt group that applies to smart-digest.
+type robotsRules struct {
+	rules      []robotsRule
+	crawlDelay time.Duration
+
+	// unreachable is set when robots.txt could not be fetched (5xx, 429 or
+	// a network error); everything on the host is then disallowed.
+	unreachable error
+}
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+}
+
+// allowed reports whether path (with query) may be fetched. The longest
+// matching rule wins; on a tie Allow wins.
+func (r *robotsRules) allowed(path string) bool {
+	if r.unreachable != nil {
+		return false
+	}
+	best, allow := -1, true
+	for _, rule := range r.rules {
+		if len(rule.pattern) < best || !matchRobots(rule.pattern, path) {
+			continue
+		}
+		if len(rule.pattern) > best || rule.allow {
+			best, allow = len(rule.pattern), rule.allow
+		}
+	}
+	return allow
+}
+
+// parseRobots extracts the rules for agent, falling back to the "*" groups
+// when no group names agent. Groups naming the same agent are merged.
+func parseRobots(r io.Reader, agent string) *robotsRules {
+	var specific, wildcard robotsRules
+	var matchedSpecific, matchedWildcard bool
+
+	// Agents of the group being read; a User-agent line after rules starts a new group
+	var isSpecific, isWildcard, inRules bool
+
+	scanner := bufio.NewScanner(io.LimitReader(r, robotsMaxBytes))
+	scanner.Buffer(make([]byte, 0, 4096), robotsMaxBytes)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		if key == "user-agent" {
+			if inRules {
+				isSpecific, isWildcard, inRules = false, false, false
+			}
+			token, _, _ := strings.Cut(strings.ToLower(value), "/")
+			switch strings.TrimSpace(token) {
+			case "*":
+				isWildcard = true
+				matchedWildcard = true
+			case agent:
+				isSpecific = true
+				matchedSpecific = true
+			}
+			continue
+		}
+
+		if key != "allow" && key != "disallow" && key != "crawl-delay" {
+			// Sitemap and unknown lines do not belong to a group
+			continue
+		}
+		inRules = true
+		var targets []*robotsRules
+		if isSpecific {
+			targets = append(targets, &specific)
+		}
+		if isWildcard {
+			targets = append(targets, &wildcard)
+		}
+		for _, t := range targets {
+			switch key {
+			case "allow", "disallow":
+				// An empty Disallow allows everything and matches nothing
+				if value != "" {
+					t.rules = append(t.rules, robotsRule{allow: key == "allow", pattern: value})
+				}
+			case "crawl-delay":
+				if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+					t.crawlDelay = time.Duration(secs * float64(time.Second))
+				}
+			}
+		}
+	}
+
+	switch {
+	case matchedSpecific:
+		return &specific
+	case matchedWildcard:
+		return &wildcard
+	default:
+		return &robotsRules{}
+	}
+}
+
+// matchRobots reports whether path matches a robots.txt pattern, where "*"
+// matches any sequence and a trailing "$" anchors the end of the path.
+func matchRobots(pattern, path string) bool {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	rest, ok := strings.CutPrefix(path, parts[0])
+	if !ok {
+		return false
+	}
+	for i, part := range parts[1:] {
+		if anchored && i == len(parts)-2 {
+			return strings.HasSuffix(rest, part)
+		}
+		idx := strings.Index(rest, part)
+		if idx < 0 {
+			return false
+		}
+		rest = rest[idx+len(part):]
+	}
+	return !anchored || rest == ""
+}
+
+// robotsCache fetches robots.txt once per origin and shares it between
+// concurrent requests.
+type robotsCache struct {
+	client *http.Client
+
+	mu      sync.Mutex
+	entries map[string]*robotsEntry
+}
+
+type robotsEntry struct {
+	ready   chan struct{} // closed once rules is set
+	rules   *robotsRules
+	expires time.Time
+}
+
+func newRobotsCache(client *http.Client) *robotsCache {
+	return &robotsCache{client: client, entries: make(map[string]*robotsEntry)}
+}
+
+// rules returns the robots.txt rules for u's origin, fetching them when
+// missing or expired.
+func (c *robotsCache) rules(ctx context.Context, u *url.URL) (*robotsRules, error) {
+	origin := u.Scheme + "://" + strings.ToLower(u.Host)
+
+	c.mu.Lock()
+	entry, ok := c.entries[origin]
+	if ok {
+		select {
+		case <-entry.ready:
+			if time.Now().After(entry.expires) {
+				ok = false
+			}
+		default:
+		}
+	}
+	if !ok {
+		entry = &robotsEntry{ready: make(chan struct{})}
+		c.entries[origin] = entry
+		c.mu.Unlock()
+
+		rules, err := c.fetch(ctx, origin)
+		if err != nil {
+			// A cancelled run says nothing about the host; let the next caller retry
+			c.mu.Lock()
+			delete(c.entries, origin)
+			c.mu.Unlock()
+			close(entry.ready)
+			return nil, err
+		}
+		entry.rules = rules
+		entry.expires = time.Now().Add(robotsTTL)
+		if rules.unreachable != nil {
+			entry.expires = time.Now().Add(robotsErrorTTL)
+		}
+		close(entry.ready)
+		return rules, nil
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	if entry.rules == nil {
+		// The fetch was cancelled in another request
+		return c.rules(ctx, u)
+	}
+	return entry.rules, nil
+}
+
+// fetch downloads and parses origin's robots.txt following RFC 9309: a
+// missing file (4xx) allows everything, an unreachable one disallows
+// everything. Only a cancelled ctx is returned as an error.
+func (c *robotsCache) fetch(ctx context.Context, origin string) (*robotsRules, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return &robotsRules{unreachable: err}, nil
+	}
+	req.Header.Set("User-Agent", UserAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return &robotsRules{unreachable: fmt.Errorf("failed to fetch robots.txt: %w", err)}, nil
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return parseRobots(resp.Body, robotsAgent), nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return &robotsRules{unreachable: fmt.Errorf("robots.txt returned HTTP %d", resp.StatusCode)}, nil
+	default:
+		return &robotsRules{}, nil
+	}
+}
+
+// robotsPath is the part of u that robots.txt rules are matched against.
+func robotsPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return path
+}
@@ -0,0 +1,194 @@
+// Package history records which articles were seen and reported across runs.
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/urlnorm"
+)
+
+// Status describes an article relative to previous runs.
+type Status int
+
+const (
+	// StatusNew means the URL has never been seen.
+	StatusNew Status = iota
+	// StatusSeen means the URL was seen before with the same content.
+	StatusSeen
+	// StatusChanged means the URL was seen before but its content differs.
+	StatusChanged
+)
+
+// String returns a short label for the status.
+func (s Status) String() string {
+	switch s {
+	case StatusSeen:
+		return "seen"
+	case StatusChanged:
+		return "changed"
+	default:
+		return "new"
+	}
+}
+
+// Record is the history of a single URL.
+type Record struct {
+	URL          string    `json:"url"`
+	ContentHash  string    `json:"content_hash"`
+	Score        int       `json:"score"`
+	FirstSeen    time.Time `json:"first_seen"`
+	LastSeen     time.Time `json:"last_seen"`
+	LastReported time.Time `json:"last_reported,omitzero"` // zero if never above threshold
+}
+
+// Store is a JSON-file backed history keyed by normalized URL.
+// It is safe for concurrent use.
+type Store struct {
+	path      string
+	retention time.Duration
+
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+// DefaultPath returns the history file under the XDG state directory.
+func DefaultPath() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "smart-digest", "history.json"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate state directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "smart-digest", "history.json"), nil
+}
+
+// Open loads the history file, starting empty if it does not exist.
+// Records not seen within retention are dropped on Save (0 keeps everything).
+func Open(path string, retention time.Duration) (*Store, error) {
+	s := &Store{
+		path:      path,
+		retention: retention,
+		records:   make(map[string]*Record),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var records []*Record
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse history %s: %w", path, err)
+	}
+	for _, r := range records {
+		s.records[urlnorm.Normalize(r.URL)] = r
+	}
+
+	return s, nil
+}
+
+// Lookup returns the status of url with the given content hash and its
+// previous record, if any.
+func (s *Store) Lookup(url, contentHash string) (Status, *Record) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[urlnorm.Normalize(url)]
+	if !ok {
+		return StatusNew, nil
+	}
+
+	prev := *r
+	if r.ContentHash != contentHash {
+		return StatusChanged, &prev
+	}
+	return StatusSeen, &prev
+}
+
+// AlreadyReported reports whether url was included in an earlier report with
+// the same content.
+func (s *Store) AlreadyReported(url, contentHash string) bool {
+	status, prev := s.Lookup(url, contentHash)
+	return status == StatusSeen && !prev.LastReported.IsZero()
+}
+
+// Observe records that url was analyzed at now. reported marks that it
+// passed the threshold and appears in this run's report.
+func (s *Store) Observe(url, contentHash string, score int, reported bool, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := urlnorm.Normalize(url)
+	r, ok := s.records[key]
+	if !ok {
+		r = &Record{URL: url, FirstSeen: now}
+		s.records[key] = r
+	}
+
+	r.ContentHash = contentHash
+	r.Score = score
+	r.LastSeen = now
+	if reported {
+		r.LastReported = now
+	}
+}
+
+// Save writes the history atomically, dropping records past retention.
+func (s *Store) Save() error {
+	s.mu.Lock()
+	records := make([]*Record, 0, len(s.records))
+	for key, r := range s.records {
+		if s.retention > 0 && time.Since(r.LastSeen) > s.retention {
+			delete(s.records, key)
+			continue
+		}
+		records = append(records, r)
+	}
+	s.mu.Unlock()
+
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode history: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	// A unique temp file keeps concurrent savers (watch, serve, a manual
+	// run) from writing into each other's file before the rename
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".history-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	// CreateTemp uses 0600; keep the permissions of a plain write
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+	return nil
+}
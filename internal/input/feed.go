@@ -0,0 +1,404 @@
+package input
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/processor"
+)
+
+// maxFeedSize caps how much of a feed document is read.
+const maxFeedSize = 10 << 20
+
+// Feed describes a feed to expand into jobs.
+type Feed struct {
+	URL      string
+	Name     string // defaults to the feed's own title
+	Project  string // copied into every job
+	MaxItems int    // 0 means no limit
+}
+
+// FeedEntry is a single item parsed from a feed document.
+type FeedEntry struct {
+	Title     string
+	URL       string
+	Published time.Time
+}
+
+// FeedReader fetches RSS 2.0, RSS 1.0, Atom and JSON Feed documents.
+type FeedReader struct {
+	fetcher *fetcher.Fetcher
+}
+
+// NewFeedReader creates a FeedReader that downloads feeds through f, so the
+// fetcher's network policy applies to feeds as it does to articles.
+func NewFeedReader(f *fetcher.Fetcher) *FeedReader {
+	return &FeedReader{fetcher: f}
+}
+
+// ReadFeeds expands every feed into jobs, skipping entries published before
+// since (zero means no limit). A failing feed does not stop the others; its
+// error is returned alongside the jobs from the feeds that succeeded.
+func (r *FeedReader) ReadFeeds(ctx context.Context, feeds []Feed, since time.Time) ([]processor.Job, []error) {
+	var jobs []processor.Job
+	var errs []error
+
+	for _, feed := range feeds {
+		feedJobs, err := r.ReadFeed(ctx, feed, since)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		jobs = append(jobs, feedJobs...)
+	}
+
+	return jobs, errs
+}
+
+// ReadFeed fetches a single feed and converts its entries into jobs.
+func (r *FeedReader) ReadFeed(ctx context.Context, feed Feed, since time.Time) ([]processor.Job, error) {
+	header := http.Header{}
+	header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml;q=0.9, */*;q=0.8")
+
+	resp, err := r.fetcher.Get(ctx, feed.URL, header)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d for feed %s", resp.StatusCode, feed.URL)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read feed %s: %w", feed.URL, err)
+	}
+
+	title, entries, err := ParseFeed(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse feed %s: %w", feed.URL, err)
+	}
+
+	name := feed.Name
+	if name == "" {
+		name = title
+	}
+	if name == "" {
+		name = feed.URL
+	}
+
+	// Relative links are relative to where the feed ended up after redirects
+	base := resp.Request.URL
+
+	var jobs []processor.Job
+	for _, entry := range entries {
+		if entry.URL == "" {
+			continue
+		}
+		if !since.IsZero() && !entry.Published.IsZero() && entry.Published.Before(since) {
+			continue
+		}
+
+		link := entry.URL
+		if ref, err := url.Parse(link); err == nil {
+			link = base.ResolveReference(ref).String()
+		}
+
+		jobs = append(jobs, processor.Job{
+			URL:       link,
+			Project:   feed.Project,
+			Title:     entry.Title,
+			Published: entry.Published,
+			Source:    name,
+		})
+
+		if feed.MaxItems > 0 && len(jobs) >= feed.MaxItems {
+			break
+		}
+	}
+
+	return jobs, nil
+}
+
+// ParseFeed detects the feed format and returns the feed title and entries.
+func ParseFeed(data []byte) (string, []FeedEntry, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return "", nil, fmt.Errorf("empty feed document")
+	}
+
+	if trimmed[0] == '{' {
+		return parseJSONFeed(trimmed)
+	}
+
+	root, err := rootElement(trimmed)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch root {
+	case "rss":
+		return parseRSS(trimmed)
+	case "RDF":
+		return parseRDF(trimmed)
+	case "feed":
+		return parseAtom(trimmed)
+	default:
+		return "", nil, fmt.Errorf("unsupported feed format: <%s>", root)
+	}
+}
+
+// rootElement returns the local name of the first XML element.
+func rootElement(data []byte) (string, error) {
+	decoder := newXMLDecoder(data)
+	for {
+		tok, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("not a feed document: %w", err)
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start.Name.Local, nil
+		}
+	}
+}
+
+// newXMLDecoder returns a lenient decoder; feeds in the wild are often sloppy.
+// Latin-1 documents are converted to UTF-8 and other declared charsets are
+// read as-is.
+func newXMLDecoder(data []byte) *xml.Decoder {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	decoder.Strict = false
+	decoder.CharsetReader = func(charset string, input io.Reader) (io.Reader, error) {
+		switch strings.ToLower(charset) {
+		case "iso-8859-1", "latin1", "latin-1":
+			raw, err := io.ReadAll(input)
+			if err != nil {
+				return nil, err
+			}
+			runes := make([]rune, len(raw))
+			for i, b := range raw {
+				runes[i] = rune(b)
+			}
+			return strings.NewReader(string(runes)), nil
+		default:
+			return input, nil
+		}
+	}
+	return decoder
+}
+
+type rssDocument struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			Title   string `xml:"title"`
+			Link    string `xml:"link"`
+			GUID    string `xml:"guid"`
+			PubDate string `xml:"pubDate"`
+			Date    string `xml:"http://purl.org/dc/elements/1.1/ date"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// parseRSS parses an RSS 2.0 document.
+func parseRSS(data []byte) (string, []FeedEntry, error) {
+	var doc rssDocument
+	if err := newXMLDecoder(data).Decode(&doc); err != nil {
+		return "", nil, err
+	}
+
+	entries := make([]FeedEntry, 0, len(doc.Channel.Items))
+	for _, item := range doc.Channel.Items {
+		link := strings.TrimSpace(item.Link)
+		if link == "" && isHTTPURL(item.GUID) {
+			link = strings.TrimSpace(item.GUID)
+		}
+		entries = append(entries, FeedEntry{
+			Title:     strings.TrimSpace(item.Title),
+			URL:       link,
+			Published: parseFeedTime(item.PubDate, item.Date),
+		})
+	}
+	return strings.TrimSpace(doc.Channel.Title), entries, nil
+}
+
+type rdfDocument struct {
+	Channel struct {
+		Title string `xml:"title"`
+	} `xml:"channel"`
+	Items []struct {
+		Title string `xml:"title"`
+		Link  string `xml:"link"`
+		Date  string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	} `xml:"item"`
+}
+
+// parseRDF parses an RSS 1.0 (RDF) document, where items are siblings of the channel.
+func parseRDF(data []byte) (string, []FeedEntry, error) {
+	var doc rdfDocument
+	if err := newXMLDecoder(data).Decode(&doc); err != nil {
+		return "", nil, err
+	}
+
+	entries := make([]FeedEntry, 0, len(doc.Items))
+	for _, item := range doc.Items {
+		entries = append(entries, FeedEntry{
+			Title:     strings.TrimSpace(item.Title),
+			URL:       strings.TrimSpace(item.Link),
+			Published: parseFeedTime(item.Date),
+		})
+	}
+	return strings.TrimSpace(doc.Channel.Title), entries, nil
+}
+
+type atomDocument struct {
+	Title   string `xml:"title"`
+	Entries []struct {
+		Title string `xml:"title"`
+		Links []struct {
+			Href string `xml:"href,attr"`
+			Rel  string `xml:"rel,attr"`
+			Type string `xml:"type,attr"`
+		} `xml:"link"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// parseAtom parses an Atom 1.0 document.
+func parseAtom(data []byte) (string, []FeedEntry, error) {
+	var doc atomDocument
+	if err := newXMLDecoder(data).Decode(&doc); err != nil {
+		return "", nil, err
+	}
+
+	entries := make([]FeedEntry, 0, len(doc.Entries))
+	for _, entry := range doc.Entries {
+		// Prefer rel="alternate" (the default when rel is absent)
+		var link string
+		for _, l := range entry.Links {
+			if l.Rel == "" || l.Rel == "alternate" {
+				link = l.Href
+				break
+			}
+		}
+		if link == "" && len(entry.Links) > 0 {
+			link = entry.Links[0].Href
+		}
+
+		entries = append(entries, FeedEntry{
+			Title:     strings.TrimSpace(entry.Title),
+			URL:       strings.TrimSpace(link),
+			Published: parseFeedTime(entry.Published, entry.Updated),
+		})
+	}
+	return strings.TrimSpace(doc.Title), entries, nil
+}
+
+type jsonFeedDocument struct {
+	Version string `json:"version"`
+	Title   string `json:"title"`
+	Items   []struct {
+		ID            string `json:"id"`
+		URL           string `json:"url"`
+		ExternalURL   string `json:"external_url"`
+		Title         string `json:"title"`
+		DatePublished string `json:"date_published"`
+		DateModified  string `json:"date_modified"`
+	} `json:"items"`
+}
+
+// parseJSONFeed parses a JSON Feed (1.0 or 1.1) document.
+func parseJSONFeed(data []byte) (string, []FeedEntry, error) {
+	var doc jsonFeedDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return "", nil, err
+	}
+
+	if !strings.HasPrefix(doc.Version, "https://jsonfeed.org/version/") {
+		return "", nil, fmt.Errorf("unsupported JSON feed version: %q", doc.Version)
+	}
+
+	entries := make([]FeedEntry, 0, len(doc.Items))
+	for _, item := range doc.Items {
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+		if link == "" && isHTTPURL(item.ID) {
+			link = item.ID
+		}
+		entries = append(entries, FeedEntry{
+			Title:     strings.TrimSpace(item.Title),
+			URL:       strings.TrimSpace(link),
+			Published: parseFeedTime(item.DatePublished, item.DateModified),
+		})
+	}
+	return strings.TrimSpace(doc.Title), entries, nil
+}
+
+// feedTimeLayouts are the date formats seen in RSS, Atom and JSON Feed.
+var feedTimeLayouts = []string{
+	time.RFC3339,
+	time.RFC3339Nano,
+	time.RFC1123Z,
+	time.RFC1123,
+	"Mon, 2 Jan 2006 15:04:05 -0700",
+	"Mon, 2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04:05 -0700",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+}
+
+// parseFeedTime returns the first value that parses as a date.
+func parseFeedTime(values ...string) time.Time {
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+		for _, layout := range feedTimeLayouts {
+			if t, err := time.Parse(layout, value); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// isHTTPURL reports whether s looks like an absolute http(s) URL.
+func isHTTPURL(s string) bool {
+	s = strings.TrimSpace(s)
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// ParseSince parses a --since value: a duration relative to now ("48h"),
+// a date ("2024-01-15") or an RFC 3339 timestamp.
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid since value %q (use a duration like 48h, a date like 2024-01-15, or RFC 3339)", value)
+}
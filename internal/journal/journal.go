@@ -0,0 +1,302 @@
+// Package journal persists the results of a run as they complete so an
+// interrupted run can be resumed without processing finished jobs again.
+package journal
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/history"
+	"github.com/taro33333/smart-digest/internal/llm"
+	"github.com/taro33333/smart-digest/internal/processor"
+)
+
+// Journal is an append-only JSON Lines file: a header with the run's jobs
+// followed by one line per finished result. It is safe for concurrent use.
+type Journal struct {
+	id   string
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+// header is the first line of a journal.
+type header struct {
+	RunID     string          `json:"run_id"`
+	StartedAt time.Time       `json:"started_at"`
+	Jobs      []processor.Job `json:"jobs"`
+}
+
+// entry is a processor.Result in serializable form.
+type entry struct {
+	Job       processor.Job       `json:"job"`
+	Article   *fetcher.Article    `json:"article,omitempty"`
+	Analysis  *llm.AnalysisResult `json:"analysis,omitempty"`
+	Model     string              `json:"model,omitempty"`   // Analysis.Model, not part of its JSON
+	Backend   string              `json:"backend,omitempty"` // Analysis.Provider, not part of its JSON
+	Error     string              `json:"error,omitempty"`
+	ErrorKind string              `json:"error_kind,omitempty"`
+	Provider  string              `json:"provider,omitempty"`
+	Cached    bool                `json:"cached,omitempty"`
+	Seen      history.Status      `json:"seen,omitempty"`
+	Previous  *history.Record     `json:"previous,omitempty"`
+	Skipped   bool                `json:"skipped,omitempty"`
+}
+
+// Error kinds kept across a resume so reports still classify them.
+const (
+	kindBlocked    = "blocked"
+	kindDisallowed = "robots"
+)
+
+// DefaultDir returns the XDG state directory for run journals.
+func DefaultDir() (string, error) {
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "smart-digest", "runs"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate state directory: %w", err)
+	}
+	return filepath.Join(home, ".local", "state", "smart-digest", "runs"), nil
+}
+
+// NewID returns a sortable run ID such as "20240115-093000-1a2b".
+func NewID(now time.Time) string {
+	var b [2]byte
+	_, _ = rand.Read(b[:])
+	return now.Format("20060102-150405") + "-" + hex.EncodeToString(b[:])
+}
+
+// Create starts a journal for a new run of jobs in dir.
+func Create(dir, id string, jobs []processor.Job) (*Journal, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create journal directory: %w", err)
+	}
+
+	j := &Journal{id: id, path: filepath.Join(dir, id+".jsonl")}
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create journal: %w", err)
+	}
+	j.file = file
+
+	if err := j.writeLine(header{RunID: id, StartedAt: time.Now(), Jobs: jobs}); err != nil {
+		file.Close()
+		os.Remove(j.path)
+		return nil, err
+	}
+	return j, nil
+}
+
+// Resume reopens the journal of run id in dir. It returns the results
+// finished so far and the jobs still to process, in their original order.
+// Further results are appended to the same journal.
+func Resume(dir, id string) (*Journal, []processor.Job, []processor.Result, error) {
+	j := &Journal{id: id, path: filepath.Join(dir, filepath.Base(id)+".jsonl")}
+
+	file, err := os.OpenFile(j.path, os.O_RDWR, 0)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil, fmt.Errorf("no journal for run %s in %s (finished runs are removed)", id, dir)
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+
+	var head header
+	var done []processor.Result
+	valid := int64(0) // end of the last complete line
+	reader := bufio.NewReader(file)
+	for first := true; ; first = false {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			// A run killed mid-write leaves a partial last line; drop it
+			break
+		}
+		if first {
+			if err := json.Unmarshal(line, &head); err != nil {
+				file.Close()
+				return nil, nil, nil, fmt.Errorf("failed to parse journal %s: %w", j.path, err)
+			}
+		} else {
+			var e entry
+			if err := json.Unmarshal(line, &e); err != nil {
+				break
+			}
+			done = append(done, e.result())
+		}
+		valid += int64(len(line))
+	}
+
+	if valid == 0 {
+		file.Close()
+		return nil, nil, nil, fmt.Errorf("journal %s has no header", j.path)
+	}
+	if err := file.Truncate(valid); err != nil {
+		file.Close()
+		return nil, nil, nil, fmt.Errorf("failed to repair journal: %w", err)
+	}
+	if _, err := file.Seek(valid, 0); err != nil {
+		file.Close()
+		return nil, nil, nil, fmt.Errorf("failed to open journal: %w", err)
+	}
+	j.file = file
+
+	return j, pending(head.Jobs, done), done, nil
+}
+
+// pending returns the jobs without a finished result. Duplicate URLs are
+// matched one result per job.
+func pending(jobs []processor.Job, done []processor.Result) []processor.Job {
+	finished := make(map[string]int, len(done))
+	for _, r := range done {
+		finished[r.Job.URL]++
+	}
+
+	var rest []processor.Job
+	for _, job := range jobs {
+		if finished[job.URL] > 0 {
+			finished[job.URL]--
+			continue
+		}
+		rest = append(rest, job)
+	}
+	return rest
+}
+
+// ID returns the run ID used with --resume.
+func (j *Journal) ID() string {
+	return j.id
+}
+
+// Path returns the journal file.
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Append records a finished result. Cancelled jobs are not finished and
+// are skipped, so a resume processes them again.
+func (j *Journal) Append(r processor.Result) error {
+	if errors.Is(r.Error, context.Canceled) {
+		return nil
+	}
+	return j.writeLine(newEntry(r))
+}
+
+// Close closes the journal and keeps it for a later resume.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.file.Close()
+}
+
+// Remove closes and deletes the journal once its run has finished.
+func (j *Journal) Remove() error {
+	j.Close()
+	if err := os.Remove(j.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to remove journal: %w", err)
+	}
+	return nil
+}
+
+// writeLine appends v as one line. Each line is a single write, so a crash
+// can at most leave a partial last line.
+func (j *Journal) writeLine(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal entry: %w", err)
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// newEntry converts a result for the journal.
+func newEntry(r processor.Result) entry {
+	e := entry{
+		Job:      r.Job,
+		Article:  r.Article,
+		Analysis: r.Analysis,
+		Provider: r.Provider,
+		Cached:   r.Cached,
+		Seen:     r.Seen,
+		Previous: r.Previous,
+		Skipped:  r.Skipped,
+	}
+	if r.Analysis != nil {
+		e.Backend = r.Analysis.Provider
+		e.Model = r.Analysis.Model
+	}
+	if r.Error != nil {
+		e.Error = r.Error.Error()
+		switch {
+		case errors.Is(r.Error, fetcher.ErrBlocked):
+			e.ErrorKind = kindBlocked
+		case errors.Is(r.Error, fetcher.ErrDisallowed):
+			e.ErrorKind = kindDisallowed
+		}
+	}
+	return e
+}
+
+// result restores the processor.Result.
+func (e entry) result() processor.Result {
+	r := processor.Result{
+		Job:      e.Job,
+		Article:  e.Article,
+		Analysis: e.Analysis,
+		Provider: e.Provider,
+		Cached:   e.Cached,
+		Seen:     e.Seen,
+		Previous: e.Previous,
+		Skipped:  e.Skipped,
+	}
+	if r.Analysis != nil {
+		r.Analysis.Provider = e.Backend
+		r.Analysis.Model = e.Model
+	}
+	if e.Error != "" {
+		r.Error = &restoredError{msg: e.Error, kind: e.ErrorKind}
+	}
+	return r
+}
+
+// restoredError is an error read back from a journal. It keeps errors.Is
+// working for the error classes reports distinguish.
+type restoredError struct {
+	msg  string
+	kind string
+}
+
+// Error implements the error interface.
+func (e *restoredError) Error() string {
+	return e.msg
+}
+
+// Is matches the sentinel of the original error's class.
+func (e *restoredError) Is(target error) bool {
+	switch e.kind {
+	case kindBlocked:
+		return target == fetcher.ErrBlocked
+	case kindDisallowed:
+		return target == fetcher.ErrDisallowed
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/llm"
+	"github.com/taro33333/smart-digest/internal/processor"
+)
+
+// limitedProvider scores articles mentioning "ok" and rate-limits the rest,
+// signalling each rate-limited call on limited.
+type limitedProvider struct {
+	limited chan struct{}
+}
+
+func (p *limitedProvider) Name() string { return "test" }
+
+func (p *limitedProvider) Analyze(ctx context.Context, req llm.Request) (*llm.AnalysisResult, error) {
+	if strings.Contains(req.Content, "ok") {
+		return &llm.AnalysisResult{Score: 80, Summary: []string{"a"}, Category: "Go"}, nil
+	}
+	p.limited <- struct{}{}
+	return nil, &llm.Error{Kind: llm.ErrRateLimited, StatusCode: http.StatusTooManyRequests, Err: errors.New("HTTP 429")}
+}
+
+func TestInterruptedRetryIsResumed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "<html><body><p>%s</p></body></html>", strings.Repeat("article "+r.URL.Path+" ", 20))
+	}))
+	defer srv.Close()
+
+	provider := &limitedProvider{limited: make(chan struct{}, 1)}
+	proc := processor.New(
+		fetcher.New(fetcher.WithPolicy(nil), fetcher.WithRobots(false, 0), fetcher.WithHostLimits(4, 0)),
+		provider, []string{"Go"}, 2, 0,
+		processor.WithRetryPolicy(processor.RetryPolicy{MaxAttempts: 3, BaseDelay: time.Hour, MaxDelay: time.Hour}),
+	)
+
+	jobs := []processor.Job{{URL: srv.URL + "/ok"}, {URL: srv.URL + "/limited"}}
+	dir := t.TempDir()
+	jnl, err := Create(dir, "run", jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Interrupt the run while the rate-limited job waits to retry
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-provider.limited
+		cancel()
+	}()
+	results := proc.Process(ctx, jobs, func(_, _ int, r *processor.Result) {
+		if err := jnl.Append(*r); err != nil {
+			t.Error(err)
+		}
+	})
+	jnl.Close()
+
+	for _, r := range results {
+		if strings.HasSuffix(r.Job.URL, "/limited") && !errors.Is(r.Error, context.Canceled) {
+			t.Errorf("interrupted job error = %v, want context.Canceled", r.Error)
+		}
+	}
+
+	resumed, pending, done, err := Resume(dir, "run")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resumed.Close()
+
+	if len(done) != 1 || !strings.HasSuffix(done[0].Job.URL, "/ok") {
+		t.Errorf("done = %v, want only the /ok job", done)
+	}
+	if len(pending) != 1 || !strings.HasSuffix(pending[0].URL, "/limited") {
+		t.Errorf("pending = %v, want the interrupted /limited job", pending)
+	}
+}
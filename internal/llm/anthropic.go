@@ -0,0 +1,162 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// anthropicAPIVersion is the Messages API version sent with every request.
+const anthropicAPIVersion = "2023-06-01"
+
+// AnthropicProvider implements Provider interface for Anthropic Messages API.
+type AnthropicProvider struct {
+	apiKey  string
+	baseURL string
+	model   string
+	prompts *Prompts
+	client  *http.Client
+}
+
+// anthropicRequest represents the request body for Anthropic Messages API.
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float64            `json:"temperature"`
+}
+
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// anthropicResponse represents the response from Anthropic Messages API.
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	StopReason string `json:"stop_reason"`
+}
+
+// anthropicError represents the error body returned by Anthropic API.
+type anthropicError struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// NewAnthropicProvider creates a new Anthropic provider.
+func NewAnthropicProvider(apiKey, baseURL, model string, prompts *Prompts) (*AnthropicProvider, error) {
+	if apiKey == "" {
+		return nil, fmt.Errorf("Anthropic API key is required")
+	}
+	if baseURL == "" {
+		baseURL = "https://api.anthropic.com"
+	}
+	if prompts == nil {
+		prompts = DefaultPrompts()
+	}
+
+	return &AnthropicProvider{
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		model:   model,
+		prompts: prompts,
+		client: &http.Client{
+			Timeout: 60 * time.Second,
+		},
+	}, nil
+}
+
+// Name returns the provider name.
+func (p *AnthropicProvider) Name() string {
+	return "Anthropic"
+}
+
+// Model returns the model name.
+func (p *AnthropicProvider) Model() string {
+	return p.model
+}
+
+// Analyze sends content to Anthropic and returns structured analysis.
+func (p *AnthropicProvider) Analyze(ctx context.Context, req Request) (*AnalysisResult, error) {
+	return analyze(ctx, p, p.prompts, req)
+}
+
+// Complete sends a conversation to Anthropic and returns the raw reply.
+func (p *AnthropicProvider) Complete(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
+	reqMessages := make([]anthropicMessage, 0, len(messages))
+	for _, m := range messages {
+		reqMessages = append(reqMessages, anthropicMessage{Role: m.Role, Content: m.Content})
+	}
+
+	// Anthropic takes the system prompt as a top-level field, not a message
+	reqBody := anthropicRequest{
+		Model:       p.model,
+		System:      systemPrompt,
+		Messages:    reqMessages,
+		MaxTokens:   1000,
+		Temperature: 0.3,
+	}
+
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := fmt.Sprintf("%s/v1/messages", p.baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", p.apiKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", classifyTransport(ctx, fmt.Errorf("Anthropic API error: %w", err))
+	}
+	defer resp.Body.Close()
+	observeRateLimits(ctx, resp.Header)
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var apiErr anthropicError
+		if json.Unmarshal(body, &apiErr) == nil && apiErr.Error.Message != "" {
+			return "", classifyStatus(resp.StatusCode, resp.Header,
+				fmt.Errorf("Anthropic API returned %d (%s): %s", resp.StatusCode, apiErr.Error.Type, apiErr.Error.Message))
+		}
+		return "", classifyStatus(resp.StatusCode, resp.Header,
+			fmt.Errorf("Anthropic API returned %d: %s", resp.StatusCode, string(body)))
+	}
+
+	var anthropicResp anthropicResponse
+	if err := json.NewDecoder(resp.Body).Decode(&anthropicResp); err != nil {
+		return "", classifyTransport(ctx, fmt.Errorf("failed to decode Anthropic response: %w", err))
+	}
+
+	// Concatenate text blocks; other block types are not expected here
+	var content strings.Builder
+	for _, block := range anthropicResp.Content {
+		if block.Type == "text" {
+			content.WriteString(block.Text)
+		}
+	}
+
+	if content.Len() == 0 {
+		return "", invalidResponse("", fmt.Errorf("no response from Anthropic"))
+	}
+
+	return content.String(), nil
+}
@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// SplitChunks splits content into pieces of at most budget bytes, preferring
+// paragraph boundaries, then line boundaries, and never splitting a
+// multi-byte character. A budget smaller than one character yields
+// one-character chunks.
+func SplitChunks(content string, budget int) []string {
+	if budget <= 0 || len(content) <= budget {
+		return []string{content}
+	}
+
+	var chunks []string
+	var current strings.Builder
+
+	flush := func() {
+		if text := strings.TrimSpace(current.String()); text != "" {
+			chunks = append(chunks, text)
+		}
+		current.Reset()
+	}
+
+	add := func(piece, sep string) {
+		if current.Len() > 0 && current.Len()+len(sep)+len(piece) > budget {
+			flush()
+		}
+		if current.Len() > 0 {
+			current.WriteString(sep)
+		}
+		current.WriteString(piece)
+	}
+
+	for _, para := range strings.Split(content, "\n\n") {
+		if len(para) <= budget {
+			add(para, "\n\n")
+			continue
+		}
+
+		// Oversized paragraph: fall back to lines, then hard cuts
+		for _, line := range strings.Split(para, "\n") {
+			for len(line) > budget {
+				cut := budget
+				for cut > 0 && !utf8.RuneStart(line[cut]) {
+					cut--
+				}
+				if cut == 0 {
+					// Budget smaller than one character: take it whole
+					_, cut = utf8.DecodeRuneInString(line)
+				}
+				add(line[:cut], "\n")
+				flush()
+				line = line[cut:]
+			}
+			add(line, "\n")
+		}
+	}
+	flush()
+
+	return chunks
+}
@@ -0,0 +1,37 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitChunks(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		budget  int
+		want    []string
+	}{
+		{name: "fits", content: "short", budget: 10, want: []string{"short"}},
+		{name: "paragraphs", content: "aaaa\n\nbbbb\n\ncccc", budget: 10, want: []string{"aaaa\n\nbbbb", "cccc"}},
+		{name: "hard cut", content: "abcdefgh", budget: 3, want: []string{"abc", "def", "gh"}},
+		{name: "multibyte boundary", content: "あいう", budget: 4, want: []string{"あ", "い", "う"}},
+		{name: "budget below one rune", content: "あいう", budget: 2, want: []string{"あ", "い", "う"}},
+		{name: "mixed below one rune", content: "aあb", budget: 1, want: []string{"a", "あ", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitChunks(tt.content, tt.budget)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("SplitChunks() = %q, want %q", got, tt.want)
+			}
+			for _, chunk := range got {
+				if !utf8.ValidString(chunk) {
+					t.Errorf("chunk %q is not valid UTF-8", chunk)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/shared"
+)
+
+// Error classes for provider failures. Use errors.Is to test an error
+// returned by Provider.Analyze against these.
+var (
+	ErrRateLimited     = errors.New("rate limited")
+	ErrTransient       = errors.New("transient error")
+	ErrAuth            = errors.New("authentication failed")
+	ErrInvalidResponse = errors.New("invalid response")
+)
+
+// Error is a classified provider error.
+type Error struct {
+	Kind       error         // one of ErrRateLimited, ErrTransient, ErrAuth, ErrInvalidResponse
+	StatusCode int           // HTTP status, 0 if not applicable
+	RetryAfter time.Duration // server-requested delay, 0 if none
+	Response   string        // raw model output for ErrInvalidResponse
+	Err        error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap allows errors.Is to match both the class and the cause.
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// RetryAfter returns the server-requested retry delay carried by err, if any.
+func RetryAfter(err error) time.Duration {
+	var llmErr *Error
+	if errors.As(err, &llmErr) {
+		return llmErr.RetryAfter
+	}
+	return 0
+}
+
+// IsRetryable reports whether err is worth retrying against the same backend.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrTransient) ||
+		errors.Is(err, ErrInvalidResponse)
+}
+
+// classifyStatus wraps an HTTP error response into a classified Error.
+// Statuses that do not map to a class are returned unclassified.
+func classifyStatus(statusCode int, header http.Header, err error) error {
+	var kind error
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		kind = ErrRateLimited
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		kind = ErrAuth
+	case statusCode == http.StatusRequestTimeout || statusCode >= 500:
+		// Anthropic uses 529 for overload, which lands here too
+		kind = ErrTransient
+	default:
+		return err
+	}
+
+	return &Error{
+		Kind:       kind,
+		StatusCode: statusCode,
+		RetryAfter: shared.ParseRetryAfter(header),
+		Err:        err,
+	}
+}
+
+// classifyTransport wraps a request-level failure (timeout, connection reset)
+// as transient. Caller cancellation is returned unchanged.
+func classifyTransport(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Kind: ErrTransient, Err: err}
+	}
+	return err
+}
+
+// invalidResponse wraps a parse failure together with the offending output.
+func invalidResponse(content string, err error) error {
+	return &Error{Kind: ErrInvalidResponse, Response: content, Err: err}
+}
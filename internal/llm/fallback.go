@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FallbackProvider tries an ordered chain of providers, moving on to the next
+// one when a call fails with a rate-limit, transient or auth error.
+type FallbackProvider struct {
+	providers []Provider
+}
+
+// NewFallbackProvider creates a provider that tries each backend in order.
+func NewFallbackProvider(providers ...Provider) (*FallbackProvider, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("at least one provider is required")
+	}
+	return &FallbackProvider{providers: providers}, nil
+}
+
+// Name returns the chain of provider names.
+func (p *FallbackProvider) Name() string {
+	names := make([]string, len(p.providers))
+	for i, provider := range p.providers {
+		names[i] = provider.Name()
+	}
+	return strings.Join(names, " → ")
+}
+
+// Analyze runs the request against each provider until one succeeds.
+func (p *FallbackProvider) Analyze(ctx context.Context, req Request) (*AnalysisResult, error) {
+	return p.try(ctx, func(provider Provider) (*AnalysisResult, error) {
+		return provider.Analyze(ctx, req)
+	})
+}
+
+// Repair re-prompts through the chain, see the package-level Repair.
+func (p *FallbackProvider) Repair(ctx context.Context, prompts *Prompts, req Request, previous string) (*AnalysisResult, error) {
+	return p.try(ctx, func(provider Provider) (*AnalysisResult, error) {
+		return Repair(ctx, provider, prompts, req, previous)
+	})
+}
+
+// try calls fn for each provider in order and returns the first success.
+// Errors that another backend cannot fix stop the chain immediately.
+func (p *FallbackProvider) try(ctx context.Context, fn func(Provider) (*AnalysisResult, error)) (*AnalysisResult, error) {
+	var errs []error
+	for _, provider := range p.providers {
+		result, err := fn(provider)
+		if err == nil {
+			return result, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", provider.Name(), err))
+
+		if ctx.Err() != nil || !shouldFallback(err) {
+			break
+		}
+	}
+
+	if len(errs) == 1 {
+		return nil, errs[0]
+	}
+	return nil, &chainError{errs: errs}
+}
+
+// chainError reports the failure of every provider in a chain.
+type chainError struct {
+	errs []error
+}
+
+// Error implements the error interface.
+func (e *chainError) Error() string {
+	msgs := make([]string, len(e.errs))
+	for i, err := range e.errs {
+		msgs[i] = err.Error()
+	}
+	return "all providers failed: " + strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the last error first so errors.As finds the most recent Retry-After.
+func (e *chainError) Unwrap() []error {
+	errs := make([]error, 0, len(e.errs))
+	for i := len(e.errs) - 1; i >= 0; i-- {
+		errs = append(errs, e.errs[i])
+	}
+	return errs
+}
+
+// shouldFallback reports whether another backend may succeed where this one failed.
+func shouldFallback(err error) bool {
+	return errors.Is(err, ErrRateLimited) ||
+		errors.Is(err, ErrTransient) ||
+		errors.Is(err, ErrAuth)
+}
@@ -13,68 +13,164 @@ type AnalysisResult struct {
 	Score    int      `json:"score"`
 	Summary  []string `json:"summary"`
 	Category string   `json:"category"`
+
+	// Provider and Model identify the backend that produced the result.
+	Provider string `json:"-"`
+	Model    string `json:"-"`
+}
+
+// Request carries an article and the user's context for a single analysis.
+type Request struct {
+	Content   string
+	Interests []string
+	Title     string
+	Project   string
+	Version   string
 }
 
 // Provider defines the interface for LLM backends.
-// This abstraction allows easy addition of new providers (Anthropic, Gemini, etc.)
+// This abstraction allows easy addition of new providers (Gemini, etc.)
 type Provider interface {
 	// Analyze sends article content to the LLM and returns analysis.
-	Analyze(ctx context.Context, articleContent string, interests []string) (*AnalysisResult, error)
+	Analyze(ctx context.Context, req Request) (*AnalysisResult, error)
 
 	// Name returns the provider name for logging.
 	Name() string
 }
 
-// NewProvider creates an appropriate LLM provider based on configuration.
-func NewProvider(cfg *config.Config) (Provider, error) {
-	switch cfg.LLMProvider {
-	case config.ProviderOpenAI:
-		return NewOpenAIProvider(cfg.APIKey, cfg.Model)
-	case config.ProviderOllama:
-		return NewOllamaProvider(cfg.OllamaURL, cfg.Model)
-	default:
-		return nil, fmt.Errorf("unsupported LLM provider: %s", cfg.LLMProvider)
+// Message is a single chat turn.
+type Message struct {
+	Role    string // "user" or "assistant"
+	Content string
+}
+
+// Completer is implemented by providers that can run a chat completion with
+// arbitrary turns. It enables follow-up prompts such as Repair.
+type Completer interface {
+	// Complete sends the conversation and returns the raw model output.
+	Complete(ctx context.Context, systemPrompt string, messages []Message) (string, error)
+}
+
+// analyze renders the prompts for req, runs them through b and parses the reply.
+// Providers implement Analyze with it.
+func analyze(ctx context.Context, b backend, prompts *Prompts, req Request) (*AnalysisResult, error) {
+	system, user, err := prompts.Render(req)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := b.Complete(ctx, system, []Message{
+		{Role: "user", Content: user},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseAnalysisResult(content, prompts.DefaultCategory())
+	if err != nil {
+		return nil, err
+	}
+	result.Provider = b.Name()
+	result.Model = b.Model()
+	return result, nil
+}
+
+// Repairer is implemented by providers that handle re-prompting themselves,
+// such as FallbackProvider.
+type Repairer interface {
+	Repair(ctx context.Context, prompts *Prompts, req Request, previous string) (*AnalysisResult, error)
+}
+
+// backend is implemented by the concrete providers in this package.
+type backend interface {
+	Provider
+	Completer
+	Model() string
+}
+
+// Repair re-prompts the model with its malformed previous output and asks for
+// corrected JSON. It fails if the provider implements neither Repairer nor Completer.
+func Repair(ctx context.Context, p Provider, prompts *Prompts, req Request, previous string) (*AnalysisResult, error) {
+	if repairer, ok := p.(Repairer); ok {
+		return repairer.Repair(ctx, prompts, req, previous)
+	}
+
+	completer, ok := p.(Completer)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support re-prompting", p.Name())
+	}
+
+	system, user, err := prompts.Render(req)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := completer.Complete(ctx, system, []Message{
+		{Role: "user", Content: user},
+		{Role: "assistant", Content: previous},
+		{Role: "user", Content: prompts.RepairPrompt()},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := parseAnalysisResult(content, prompts.DefaultCategory())
+	if err != nil {
+		return nil, err
 	}
+	result.Provider = p.Name()
+	if b, ok := p.(backend); ok {
+		result.Model = b.Model()
+	}
+	return result, nil
 }
 
-// BuildSystemPrompt generates the system prompt for LLM analysis.
-func BuildSystemPrompt(interests []string) string {
-	interestList := ""
-	for i, interest := range interests {
-		if i > 0 {
-			interestList += ", "
+// NewProvider creates an appropriate LLM provider based on configuration.
+// When fallback providers are configured the result is a FallbackProvider
+// trying llm_provider first. A nil prompts uses DefaultPrompts.
+func NewProvider(cfg *config.Config, prompts *Prompts) (Provider, error) {
+	primary, err := newBackend(cfg, cfg.LLMProvider, cfg.Model, prompts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cfg.Fallback) == 0 {
+		return primary, nil
+	}
+
+	chain := []Provider{primary}
+	for _, fb := range cfg.Fallback {
+		provider, err := newBackend(cfg, fb.Provider, fb.Model, prompts)
+		if err != nil {
+			return nil, fmt.Errorf("fallback %s: %w", fb.Provider, err)
 		}
-		interestList += interest
+		chain = append(chain, provider)
 	}
 
-	return fmt.Sprintf(`あなたは優秀なエンジニアのアシスタントです。以下の記事本文を読み、ユーザーの興味関心領域: [%s] に基づいて 0〜100点でスコアリングし、日本語で要約してください。
-
-## スコアリング基準
-- 90-100: 興味関心に直接関連し、実務で即座に活用できる内容
-- 70-89: 興味関心に関連があり、参考になる内容
-- 50-69: 間接的に関連があるかもしれない内容
-- 30-49: 関連性が薄い内容
-- 0-29: 興味関心とほぼ無関係
-
-## 出力形式
-必ず以下のJSON形式のみで出力してください。他の文章は一切含めないでください。
-
-{
-  "score": <0-100の整数>,
-  "summary": [
-    "<要点1: 1文で簡潔に>",
-    "<要点2: 1文で簡潔に>",
-    "<要点3: 1文で簡潔に>"
-  ],
-  "category": "<最も適切な1つのカテゴリタグ>"
-}`, interestList)
+	return NewFallbackProvider(chain...)
 }
 
-// BuildUserPrompt generates the user prompt with article content.
-func BuildUserPrompt(articleContent string) string {
-	return fmt.Sprintf(`以下の記事を分析してください:
+// newBackend creates a single provider of the given kind using the shared
+// connection settings from cfg.
+func newBackend(cfg *config.Config, kind config.LLMProvider, model string, prompts *Prompts) (Provider, error) {
+	switch kind {
+	case config.ProviderOpenAI:
+		return NewOpenAIProvider(cfg.APIKey, model, OpenAIOptions{
+			BaseURL:      cfg.OpenAI.BaseURL,
+			Azure:        cfg.OpenAI.APIType == "azure",
+			APIVersion:   cfg.OpenAI.APIVersion,
+			Deployment:   cfg.OpenAI.Deployment,
+			Organization: cfg.OpenAI.Organization,
+			Headers:      cfg.OpenAI.Headers,
 
----
-%s
----`, articleContent)
+			StructuredOutput: cfg.StructuredOutput,
+			Prompts:          prompts,
+		})
+	case config.ProviderOllama:
+		return NewOllamaProvider(cfg.OllamaURL, model, cfg.StructuredOutput, prompts)
+	case config.ProviderAnthropic:
+		return NewAnthropicProvider(cfg.AnthropicAPIKey, cfg.AnthropicURL, model, prompts)
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider: %s", kind)
+	}
 }
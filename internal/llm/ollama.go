@@ -12,9 +12,11 @@ import (
 
 // OllamaProvider implements Provider interface for local Ollama server.
 type OllamaProvider struct {
-	baseURL string
-	model   string
-	client  *http.Client
+	baseURL    string
+	model      string
+	structured bool
+	prompts    *Prompts
+	client     *http.Client
 }
 
 // ollamaRequest represents the request body for Ollama API.
@@ -22,6 +24,7 @@ type ollamaRequest struct {
 	Model    string          `json:"model"`
 	Messages []ollamaMessage `json:"messages"`
 	Stream   bool            `json:"stream"`
+	Format   json.RawMessage `json:"format,omitempty"`
 	Options  ollamaOptions   `json:"options"`
 }
 
@@ -44,14 +47,20 @@ type ollamaResponse struct {
 }
 
 // NewOllamaProvider creates a new Ollama provider.
-func NewOllamaProvider(baseURL, model string) (*OllamaProvider, error) {
+// When structured is true the AnalysisResult schema is sent as the format field.
+func NewOllamaProvider(baseURL, model string, structured bool, prompts *Prompts) (*OllamaProvider, error) {
 	if baseURL == "" {
 		baseURL = "http://localhost:11434"
 	}
+	if prompts == nil {
+		prompts = DefaultPrompts()
+	}
 
 	return &OllamaProvider{
-		baseURL: baseURL,
-		model:   model,
+		baseURL:    baseURL,
+		model:      model,
+		structured: structured,
+		prompts:    prompts,
 		client: &http.Client{
 			Timeout: 120 * time.Second, // Longer timeout for local LLM
 		},
@@ -63,52 +72,65 @@ func (p *OllamaProvider) Name() string {
 	return "Ollama"
 }
 
+// Model returns the model name.
+func (p *OllamaProvider) Model() string {
+	return p.model
+}
+
 // Analyze sends content to Ollama and returns structured analysis.
-func (p *OllamaProvider) Analyze(ctx context.Context, articleContent string, interests []string) (*AnalysisResult, error) {
-	systemPrompt := BuildSystemPrompt(interests)
-	userPrompt := BuildUserPrompt(articleContent)
+func (p *OllamaProvider) Analyze(ctx context.Context, req Request) (*AnalysisResult, error) {
+	return analyze(ctx, p, p.prompts, req)
+}
+
+// Complete sends a conversation to Ollama and returns the raw reply.
+func (p *OllamaProvider) Complete(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
+	reqMessages := []ollamaMessage{{Role: "system", Content: systemPrompt}}
+	for _, m := range messages {
+		reqMessages = append(reqMessages, ollamaMessage{Role: m.Role, Content: m.Content})
+	}
 
 	reqBody := ollamaRequest{
-		Model: p.model,
-		Messages: []ollamaMessage{
-			{Role: "system", Content: systemPrompt},
-			{Role: "user", Content: userPrompt},
-		},
-		Stream: false,
+		Model:    p.model,
+		Messages: reqMessages,
+		Stream:   false,
 		Options: ollamaOptions{
 			Temperature: 0.3,
 			NumPredict:  1000,
 		},
 	}
+	if p.structured {
+		reqBody.Format = analysisResultSchema
+	}
 
 	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request: %w", err)
+		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	url := fmt.Sprintf("%s/api/chat", p.baseURL)
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonData))
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := p.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("Ollama API error: %w", err)
+		return "", classifyTransport(ctx, fmt.Errorf("Ollama API error: %w", err))
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("Ollama API returned %d: %s", resp.StatusCode, string(body))
+		return "", classifyStatus(resp.StatusCode, resp.Header,
+			fmt.Errorf("Ollama API returned %d: %s", resp.StatusCode, string(body)))
 	}
 
 	var ollamaResp ollamaResponse
 	if err := json.NewDecoder(resp.Body).Decode(&ollamaResp); err != nil {
-		return nil, fmt.Errorf("failed to decode Ollama response: %w", err)
+		return "", classifyTransport(ctx, fmt.Errorf("failed to decode Ollama response: %w", err))
 	}
 
-	return parseAnalysisResult(ollamaResp.Message.Content)
+	return ollamaResp.Message.Content, nil
 }
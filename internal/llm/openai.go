@@ -2,102 +2,234 @@ package llm
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	openai "github.com/sashabaranov/go-openai"
 )
 
 // OpenAIProvider implements Provider interface for OpenAI API.
 type OpenAIProvider struct {
-	client *openai.Client
-	model  string
+	client     *openai.Client
+	model      string
+	structured bool
+	prompts    *Prompts
+
+	// unstructured is set once the server rejected response_format, so
+	// later calls leave it out.
+	unstructured atomic.Bool
+}
+
+// OpenAIOptions configures how OpenAIProvider reaches an OpenAI-compatible endpoint.
+// The zero value targets the official OpenAI API.
+type OpenAIOptions struct {
+	BaseURL      string            // e.g. http://localhost:8000/v1 for vLLM
+	Azure        bool              // use Azure OpenAI URL layout and api-key auth
+	APIVersion   string            // Azure api-version query parameter
+	Deployment   string            // Azure deployment name (defaults to the model name)
+	Organization string            // OpenAI-Organization header
+	Headers      map[string]string // extra headers sent with every request
+
+	// StructuredOutput requests a JSON-schema constrained response via
+	// response_format. Servers that reject the parameter with HTTP 400 are
+	// retried without it, and it is not sent to them again.
+	StructuredOutput bool
+
+	// Prompts renders the analysis prompts. Nil uses DefaultPrompts.
+	Prompts *Prompts
 }
 
 // NewOpenAIProvider creates a new OpenAI provider.
-func NewOpenAIProvider(apiKey, model string) (*OpenAIProvider, error) {
-	if apiKey == "" {
+func NewOpenAIProvider(apiKey, model string, opts OpenAIOptions) (*OpenAIProvider, error) {
+	// Self-hosted OpenAI-compatible servers often run without authentication
+	if apiKey == "" && opts.BaseURL == "" {
 		return nil, fmt.Errorf("OpenAI API key is required")
 	}
 
-	client := openai.NewClient(apiKey)
+	var clientConfig openai.ClientConfig
+	if opts.Azure {
+		if opts.BaseURL == "" {
+			return nil, fmt.Errorf("base URL is required for Azure OpenAI")
+		}
+		clientConfig = openai.DefaultAzureConfig(apiKey, opts.BaseURL)
+		if opts.APIVersion != "" {
+			clientConfig.APIVersion = opts.APIVersion
+		}
+		if opts.Deployment != "" {
+			deployment := opts.Deployment
+			clientConfig.AzureModelMapperFunc = func(string) string {
+				return deployment
+			}
+		}
+	} else {
+		clientConfig = openai.DefaultConfig(apiKey)
+		if opts.BaseURL != "" {
+			clientConfig.BaseURL = strings.TrimSuffix(opts.BaseURL, "/")
+		}
+	}
+	clientConfig.OrgID = opts.Organization
+
+	clientConfig.HTTPClient = &http.Client{
+		Timeout: 60 * time.Second,
+		Transport: &headerTransport{
+			base:    http.DefaultTransport,
+			headers: opts.Headers,
+		},
+	}
+
+	prompts := opts.Prompts
+	if prompts == nil {
+		prompts = DefaultPrompts()
+	}
+
 	return &OpenAIProvider{
-		client: client,
-		model:  model,
+		client:     openai.NewClientWithConfig(clientConfig),
+		model:      model,
+		structured: opts.StructuredOutput,
+		prompts:    prompts,
 	}, nil
 }
 
+// headerTransport adds a fixed set of headers to every outgoing request and
+// hands response headers back to callers that asked via withHeaderCapture.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if dst, ok := req.Context().Value(headerCaptureKey{}).(*http.Header); ok && resp != nil {
+		*dst = resp.Header
+	}
+	return resp, err
+}
+
 // Name returns the provider name.
 func (p *OpenAIProvider) Name() string {
 	return "OpenAI"
 }
 
+// Model returns the model name.
+func (p *OpenAIProvider) Model() string {
+	return p.model
+}
+
 // Analyze sends content to OpenAI and returns structured analysis.
-func (p *OpenAIProvider) Analyze(ctx context.Context, articleContent string, interests []string) (*AnalysisResult, error) {
-	systemPrompt := BuildSystemPrompt(interests)
-	userPrompt := BuildUserPrompt(articleContent)
-
-	resp, err := p.client.CreateChatCompletion(
-		ctx,
-		openai.ChatCompletionRequest{
-			Model: p.model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: systemPrompt,
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: userPrompt,
-				},
-			},
-			Temperature: 0.3, // Lower temperature for consistent JSON output
-			MaxTokens:   500,
+func (p *OpenAIProvider) Analyze(ctx context.Context, req Request) (*AnalysisResult, error) {
+	return analyze(ctx, p, p.prompts, req)
+}
+
+// Complete sends a conversation to OpenAI and returns the raw reply.
+func (p *OpenAIProvider) Complete(ctx context.Context, systemPrompt string, messages []Message) (string, error) {
+	reqMessages := []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: systemPrompt,
 		},
-	)
+	}
+	for _, m := range messages {
+		reqMessages = append(reqMessages, openai.ChatCompletionMessage{
+			Role:    m.Role,
+			Content: m.Content,
+		})
+	}
+
+	req := openai.ChatCompletionRequest{
+		Model:       p.model,
+		Messages:    reqMessages,
+		Temperature: 0.3, // Lower temperature for consistent JSON output
+		MaxTokens:   500,
+	}
+	structured := p.structured && !p.unstructured.Load()
+	if structured {
+		req.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   "analysis_result",
+				Schema: analysisResultSchema,
+				Strict: true,
+			},
+		}
+	}
 
+	resp, header, err := p.createChatCompletion(ctx, req)
+	if err != nil && structured && rejectsResponseFormat(err) {
+		// Many OpenAI-compatible servers do not support json_schema
+		p.unstructured.Store(true)
+		req.ResponseFormat = nil
+		resp, header, err = p.createChatCompletion(ctx, req)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("OpenAI API error: %w", err)
+		return "", classifyOpenAIError(ctx, header, fmt.Errorf("OpenAI API error: %w", err))
 	}
 
 	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no response from OpenAI")
+		return "", invalidResponse("", fmt.Errorf("no response from OpenAI"))
 	}
 
-	content := resp.Choices[0].Message.Content
-	return parseAnalysisResult(content)
+	return resp.Choices[0].Message.Content, nil
 }
 
-// parseAnalysisResult extracts JSON from LLM response.
-func parseAnalysisResult(content string) (*AnalysisResult, error) {
-	// Clean up response - sometimes LLMs wrap JSON in markdown code blocks
-	content = strings.TrimSpace(content)
-	content = strings.TrimPrefix(content, "```json")
-	content = strings.TrimPrefix(content, "```")
-	content = strings.TrimSuffix(content, "```")
-	content = strings.TrimSpace(content)
-
-	var result AnalysisResult
-	if err := json.Unmarshal([]byte(content), &result); err != nil {
-		return nil, fmt.Errorf("failed to parse LLM response as JSON: %w\nResponse was: %s", err, content)
-	}
+// createChatCompletion sends req and reports its rate-limit headers.
+func (p *OpenAIProvider) createChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, http.Header, error) {
+	// go-openai does not expose response headers, so capture them for
+	// Retry-After and the rate-limit quotas
+	var header http.Header
+	resp, err := p.client.CreateChatCompletion(withHeaderCapture(ctx, &header), req)
+	observeRateLimits(ctx, header)
+	return resp, header, err
+}
 
-	// Validate result
-	if result.Score < 0 {
-		result.Score = 0
+// rejectsResponseFormat reports whether err is an HTTP 400 that blames the
+// response_format parameter.
+func rejectsResponseFormat(err error) bool {
+	status := 0
+	var apiErr *openai.APIError
+	var reqErr *openai.RequestError
+	switch {
+	case errors.As(err, &apiErr):
+		status = apiErr.HTTPStatusCode
+	case errors.As(err, &reqErr):
+		status = reqErr.HTTPStatusCode
 	}
-	if result.Score > 100 {
-		result.Score = 100
+	if status != http.StatusBadRequest {
+		return false
 	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "response_format") || strings.Contains(msg, "json_schema")
+}
 
-	if len(result.Summary) == 0 {
-		return nil, fmt.Errorf("LLM returned empty summary")
+// classifyOpenAIError maps go-openai error types onto the llm error classes.
+func classifyOpenAIError(ctx context.Context, header http.Header, err error) error {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return classifyStatus(apiErr.HTTPStatusCode, header, err)
 	}
 
-	if result.Category == "" {
-		result.Category = "未分類"
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return classifyStatus(reqErr.HTTPStatusCode, header, err)
 	}
 
-	return &result, nil
+	return classifyTransport(ctx, err)
+}
+
+type headerCaptureKey struct{}
+
+// withHeaderCapture asks headerTransport to store the response headers of
+// requests made with the returned context into dst.
+func withHeaderCapture(ctx context.Context, dst *http.Header) context.Context {
+	return context.WithValue(ctx, headerCaptureKey{}, dst)
 }
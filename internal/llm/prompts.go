@@ -0,0 +1,232 @@
+package llm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+)
+
+//go:embed prompts
+var builtinPrompts embed.FS
+
+// Supported summary languages for the built-in prompts.
+const (
+	LanguageJapanese = "ja"
+	LanguageEnglish  = "en"
+)
+
+// PromptData is the data available to prompt templates.
+type PromptData struct {
+	Interests    []string
+	InterestList string // Interests joined with ", "
+	Title        string
+	Project      string
+	Version      string
+	Content      string
+}
+
+// languagePack holds the fixed phrases that are not user-templated.
+type languagePack struct {
+	repair        string
+	chunkHeader   string // index, total
+	reduceHeader  string
+	titleLabel    string
+	partLine      string // index, score, category
+	uncategorized string
+}
+
+var languagePacks = map[string]languagePack{
+	LanguageJapanese: {
+		repair:        "直前の出力は有効なJSONではありませんでした。説明文やコードブロックを含めず、指定したJSON形式のオブジェクトのみを出力し直してください。",
+		chunkHeader:   "[長い記事のパート %d/%d です。このパートの内容のみを分析してください]",
+		reduceHeader:  "[以下は長い記事を分割して分析した各パートの要約です。記事全体として分析してください]",
+		titleLabel:    "タイトル",
+		partLine:      "## パート %d (スコア: %d, カテゴリ: %s)",
+		uncategorized: "未分類",
+	},
+	LanguageEnglish: {
+		repair:        "Your previous output was not valid JSON. Respond again with only the JSON object in the specified format, without any explanation or code fences.",
+		chunkHeader:   "[This is part %d/%d of a long article. Analyze only the content of this part]",
+		reduceHeader:  "[Below are summaries of each part of a long article. Analyze the article as a whole]",
+		titleLabel:    "Title",
+		partLine:      "## Part %d (score: %d, category: %s)",
+		uncategorized: "Uncategorized",
+	},
+}
+
+// Prompts renders the prompts sent to LLM providers.
+type Prompts struct {
+	system  *template.Template
+	user    *template.Template
+	lang    languagePack
+	version string
+}
+
+// DefaultPrompts returns the built-in Japanese prompts.
+func DefaultPrompts() *Prompts {
+	prompts, err := LoadPrompts(LanguageJapanese, "", "")
+	if err != nil {
+		panic(err) // built-in templates are covered by TestBuiltinPrompts
+	}
+	return prompts
+}
+
+// LoadPrompts builds prompts for the given language. Non-empty systemPath or
+// userPath replace the corresponding built-in template with a user file.
+func LoadPrompts(language, systemPath, userPath string) (*Prompts, error) {
+	if language == "" {
+		language = LanguageJapanese
+	}
+	lang, ok := languagePacks[language]
+	if !ok {
+		return nil, fmt.Errorf("unsupported language: %s (must be 'ja' or 'en')", language)
+	}
+
+	system, systemText, err := loadTemplate("system", language, systemPath)
+	if err != nil {
+		return nil, err
+	}
+	user, userText, err := loadTemplate("user", language, userPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(language + "\x00" + systemText + "\x00" + userText))
+	p := &Prompts{
+		system:  system,
+		user:    user,
+		lang:    lang,
+		version: hex.EncodeToString(sum[:8]),
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// loadTemplate parses a user template file, or the built-in one if path is
+// empty, and returns it along with its source text.
+func loadTemplate(name, language, path string) (*template.Template, string, error) {
+	var text []byte
+	var err error
+	if path != "" {
+		text, err = os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to read %s prompt template: %w", name, err)
+		}
+	} else {
+		text, err = builtinPrompts.ReadFile("prompts/" + language + "/" + name + ".tmpl")
+		if err != nil {
+			return nil, "", fmt.Errorf("missing built-in %s prompt for %s: %w", name, language, err)
+		}
+	}
+
+	tmpl, err := template.New(name).Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(string(text))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s prompt template: %w", name, err)
+	}
+	return tmpl, string(text), nil
+}
+
+// Version identifies the templates and language, so cached analyses can be
+// invalidated when prompts change.
+func (p *Prompts) Version() string {
+	return p.version
+}
+
+// validate renders both templates with sample data, so references to
+// fields PromptData does not have fail at load time, and checks that the
+// system prompt still asks for every required JSON key and the user prompt
+// still carries the article content.
+func (p *Prompts) validate() error {
+	const marker = "__SMART_DIGEST_CONTENT__"
+	sample := Request{
+		Content:   marker,
+		Interests: []string{"Go"},
+		Title:     "Title",
+		Project:   "project",
+		Version:   "1.0.0",
+	}
+
+	system, user, err := p.Render(sample)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range []string{"score", "summary", "category"} {
+		if !strings.Contains(system, key) {
+			return fmt.Errorf("system prompt template must mention the %q JSON key", key)
+		}
+	}
+
+	if !strings.Contains(user, marker) {
+		return fmt.Errorf("user prompt template must include {{.Content}}")
+	}
+
+	return nil
+}
+
+// Render produces the system and user prompts for a request.
+func (p *Prompts) Render(req Request) (system, user string, err error) {
+	data := PromptData{
+		Interests:    req.Interests,
+		InterestList: strings.Join(req.Interests, ", "),
+		Title:        req.Title,
+		Project:      req.Project,
+		Version:      req.Version,
+		Content:      req.Content,
+	}
+
+	var buf bytes.Buffer
+	if err := p.system.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("failed to render system prompt: %w", err)
+	}
+	system = strings.TrimSpace(buf.String())
+
+	buf.Reset()
+	if err := p.user.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("failed to render user prompt: %w", err)
+	}
+	user = strings.TrimSpace(buf.String())
+
+	return system, user, nil
+}
+
+// RepairPrompt asks the model to resend its previous answer as valid JSON.
+func (p *Prompts) RepairPrompt() string {
+	return p.lang.repair
+}
+
+// DefaultCategory is used when the model omits a category.
+func (p *Prompts) DefaultCategory() string {
+	return p.lang.uncategorized
+}
+
+// ChunkContent labels one part of a long article for the map step.
+func (p *Prompts) ChunkContent(index, total int, chunk string) string {
+	return fmt.Sprintf(p.lang.chunkHeader, index, total) + "\n\n" + chunk
+}
+
+// ReduceContent combines per-chunk analyses into input for the reduce step.
+func (p *Prompts) ReduceContent(title string, partials []*AnalysisResult) string {
+	var b strings.Builder
+	b.WriteString(p.lang.reduceHeader + "\n\n")
+	if title != "" {
+		fmt.Fprintf(&b, "%s: %s\n\n", p.lang.titleLabel, title)
+	}
+	for i, r := range partials {
+		fmt.Fprintf(&b, p.lang.partLine+"\n", i+1, r.Score, r.Category)
+		for _, point := range r.Summary {
+			fmt.Fprintf(&b, "- %s\n", point)
+		}
+		b.WriteString("\n")
+	}
+	return strings.TrimSpace(b.String())
+}
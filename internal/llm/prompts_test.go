@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuiltinPrompts(t *testing.T) {
+	for language := range languagePacks {
+		t.Run(language, func(t *testing.T) {
+			p, err := LoadPrompts(language, "", "")
+			if err != nil {
+				t.Fatalf("LoadPrompts(%q) error = %v", language, err)
+			}
+			_, user, err := p.Render(Request{Content: "article body", Interests: []string{"Go", "Rust"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(user, "article body") {
+				t.Errorf("user prompt does not contain the content: %q", user)
+			}
+		})
+	}
+}
+
+func TestLoadPromptsValidatesUserTemplates(t *testing.T) {
+	tests := []struct {
+		name    string
+		user    string
+		wantErr string
+	}{
+		{name: "valid", user: "{{.Title}}\n{{.Content}}"},
+		{name: "unknown field", user: "{{.Body}}\n{{.Content}}", wantErr: "failed to render user prompt"},
+		{name: "missing content", user: "{{.Title}}", wantErr: "must include {{.Content}}"},
+		{name: "syntax error", user: "{{.Content", wantErr: "failed to parse user prompt template"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "user.tmpl")
+			if err := os.WriteFile(path, []byte(tt.user), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := LoadPrompts(LanguageEnglish, "", path)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("LoadPrompts() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("LoadPrompts() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimit is one quota reported by a provider.
+type RateLimit struct {
+	Limit     int           // per minute, 0 if not reported
+	Remaining int           // left in the current window
+	Reset     time.Duration // until the quota is replenished, 0 if not reported
+}
+
+// RateLimits are the quotas carried by a response's x-ratelimit-* (OpenAI
+// and compatible gateways) or anthropic-ratelimit-* headers. A nil field
+// was not reported.
+type RateLimits struct {
+	Requests *RateLimit
+	Tokens   *RateLimit
+}
+
+type rateLimitObserverKey struct{}
+
+// WithRateLimitObserver returns a context that makes providers pass the
+// rate-limit headers of every response, successful or not, to fn.
+func WithRateLimitObserver(ctx context.Context, fn func(RateLimits)) context.Context {
+	return context.WithValue(ctx, rateLimitObserverKey{}, fn)
+}
+
+// observeRateLimits reports the rate-limit headers in header to the
+// observer registered on ctx, if any.
+func observeRateLimits(ctx context.Context, header http.Header) {
+	fn, ok := ctx.Value(rateLimitObserverKey{}).(func(RateLimits))
+	if !ok || header == nil {
+		return
+	}
+
+	limits := RateLimits{
+		Requests: parseRateLimit(header, "x-ratelimit-%s-requests"),
+		Tokens:   parseRateLimit(header, "x-ratelimit-%s-tokens"),
+	}
+	if limits.Requests == nil {
+		limits.Requests = parseRateLimit(header, "anthropic-ratelimit-requests-%s")
+	}
+	if limits.Tokens == nil {
+		limits.Tokens = parseRateLimit(header, "anthropic-ratelimit-tokens-%s")
+	}
+	if limits.Requests != nil || limits.Tokens != nil {
+		fn(limits)
+	}
+}
+
+// parseRateLimit reads one quota; format has a %s for "limit",
+// "remaining" and "reset". It returns nil without a remaining header.
+func parseRateLimit(header http.Header, format string) *RateLimit {
+	name := func(field string) string {
+		return fmt.Sprintf(format, field)
+	}
+
+	remaining, err := strconv.Atoi(header.Get(name("remaining")))
+	if err != nil {
+		return nil
+	}
+	limit, _ := strconv.Atoi(header.Get(name("limit")))
+	return &RateLimit{
+		Limit:     limit,
+		Remaining: remaining,
+		Reset:     parseReset(header.Get(name("reset"))),
+	}
+}
+
+// parseReset reads a reset value: a Go-style duration ("1s", "6m0s",
+// "20ms") as sent by OpenAI, an RFC 3339 time as sent by Anthropic, or
+// plain seconds.
+func parseReset(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return d
+	}
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return max(time.Until(at), 0)
+	}
+	if secs, err := strconv.ParseFloat(value, 64); err == nil && secs > 0 {
+		return time.Duration(secs * float64(time.Second))
+	}
+	return 0
+}
@@ -0,0 +1,118 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// analysisResultSchema is the JSON schema of AnalysisResult, sent to backends
+// that support schema-constrained output (OpenAI response_format, Ollama format).
+var analysisResultSchema = json.RawMessage(`{
+  "type": "object",
+  "properties": {
+    "score": {"type": "integer", "description": "Relevance score from 0 to 100"},
+    "summary": {"type": "array", "items": {"type": "string"}, "description": "Three key points"},
+    "category": {"type": "string", "description": "Single category tag"}
+  },
+  "required": ["score", "summary", "category"],
+  "additionalProperties": false
+}`)
+
+// parseAnalysisResult extracts JSON from LLM response.
+// fallbackCategory is used when the model leaves the category empty.
+func parseAnalysisResult(content, fallbackCategory string) (*AnalysisResult, error) {
+	// Clean up response - sometimes LLMs wrap JSON in markdown code blocks
+	content = strings.TrimSpace(content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	content = strings.TrimSpace(content)
+
+	var result AnalysisResult
+	if err := json.Unmarshal([]byte(content), &result); err != nil {
+		// Chatty models wrap the object in prose; fall back to the first
+		// balanced JSON object found anywhere in the text
+		extracted, ok := extractJSONObject(content)
+		if !ok {
+			return nil, invalidResponse(content, fmt.Errorf("failed to parse LLM response as JSON: %w\nResponse was: %s", err, content))
+		}
+		result = AnalysisResult{}
+		if err := json.Unmarshal([]byte(extracted), &result); err != nil {
+			return nil, invalidResponse(content, fmt.Errorf("failed to parse LLM response as JSON: %w\nResponse was: %s", err, content))
+		}
+	}
+
+	// Validate result
+	if result.Score < 0 {
+		result.Score = 0
+	}
+	if result.Score > 100 {
+		result.Score = 100
+	}
+
+	if len(result.Summary) == 0 {
+		return nil, invalidResponse(content, fmt.Errorf("LLM returned empty summary"))
+	}
+
+	if result.Category == "" {
+		result.Category = fallbackCategory
+	}
+
+	return &result, nil
+}
+
+// extractJSONObject returns the first balanced {...} span in text that is
+// valid JSON. Braces inside string literals are ignored.
+func extractJSONObject(text string) (string, bool) {
+	for start := strings.IndexByte(text, '{'); start >= 0; {
+		if end := matchBrace(text, start); end > start {
+			candidate := text[start : end+1]
+			if json.Valid([]byte(candidate)) {
+				return candidate, true
+			}
+		}
+
+		next := strings.IndexByte(text[start+1:], '{')
+		if next < 0 {
+			break
+		}
+		start += next + 1
+	}
+	return "", false
+}
+
+// matchBrace returns the index of the brace closing the one at start, or -1.
+func matchBrace(text string, start int) int {
+	depth := 0
+	inString := false
+	escaped := false
+
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/taro33333/smart-digest/internal/output"
+	"github.com/taro33333/smart-digest/internal/shared"
+)
+
+// Discord limits for webhook messages.
+const (
+	discordMaxContent     = 2000
+	discordMaxEmbeds      = 10
+	discordMaxTotal       = 6000 // all embed text in one message
+	discordMaxTitle       = 256
+	discordMaxDescription = 4096
+	discordMaxFooter      = 2048
+)
+
+// Discord posts the digest to a Discord webhook, one embed per article.
+// Embeds are split across messages to stay within Discord's limits.
+type Discord struct {
+	client
+}
+
+// NewDiscord creates a sink for the given webhook URL.
+func NewDiscord(webhookURL string, opts ...Option) *Discord {
+	return &Discord{client: newClient("discord", webhookURL, opts)}
+}
+
+// discordMessage is a webhook execute payload.
+type discordMessage struct {
+	Content         string              `json:"content,omitempty"`
+	Embeds          []discordEmbed      `json:"embeds,omitempty"`
+	AllowedMentions discordAllowMention `json:"allowed_mentions"`
+}
+
+// discordAllowMention limits which mentions in the content may ping. An
+// empty (not null) parse list disables pings from article text such as
+// "@everyone".
+type discordAllowMention struct {
+	Parse []string `json:"parse"`
+}
+
+// newDiscordMessage returns a message that pings nobody.
+func newDiscordMessage(content string) discordMessage {
+	return discordMessage{
+		Content:         content,
+		AllowedMentions: discordAllowMention{Parse: []string{}},
+	}
+}
+
+type discordEmbed struct {
+	Title       string         `json:"title,omitempty"`
+	URL         string         `json:"url,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color,omitempty"`
+	Footer      *discordFooter `json:"footer,omitempty"`
+}
+
+type discordFooter struct {
+	Text string `json:"text"`
+}
+
+// size is the embed's contribution to the per-message character limit.
+func (e discordEmbed) size() int {
+	n := utf8.RuneCountInString(e.Title) + utf8.RuneCountInString(e.Description)
+	if e.Footer != nil {
+		n += utf8.RuneCountInString(e.Footer.Text)
+	}
+	return n
+}
+
+// Send posts the report as one or more messages.
+func (s *Discord) Send(ctx context.Context, d *Digest) error {
+	for i, msg := range discordMessages(d.Report) {
+		body, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+		if err := s.post(ctx, body, nil); err != nil {
+			return fmt.Errorf("message %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// discordMessages renders the report. The first message carries the header
+// and errors are sent as a final red embed.
+func discordMessages(report output.JSONReport) []discordMessage {
+	header := fmt.Sprintf("**Smart Digest %s**\n%s",
+		report.Run.GeneratedAt.Local().Format("2006-01-02"), summaryLine(report.Run))
+	if len(report.Items) == 0 {
+		header += "\n該当する記事はありませんでした。"
+	}
+
+	var embeds []discordEmbed
+	for _, item := range report.Items {
+		embeds = append(embeds, discordItem(item))
+	}
+	if len(report.Errors) > 0 {
+		var b strings.Builder
+		for _, e := range report.Errors {
+			fmt.Fprintf(&b, "• %s: `%s`\n", e.URL, e.Error)
+		}
+		embeds = append(embeds, discordEmbed{
+			Title:       fmt.Sprintf("⚠️ エラー (%d件)", len(report.Errors)),
+			Description: shared.Truncate(b.String(), discordMaxDescription),
+			Color:       0xcf222e,
+		})
+	}
+
+	messages := []discordMessage{newDiscordMessage(shared.Truncate(header, discordMaxContent))}
+	total := 0
+	for _, e := range embeds {
+		last := &messages[len(messages)-1]
+		if len(last.Embeds) == discordMaxEmbeds || total+e.size() > discordMaxTotal {
+			messages = append(messages, newDiscordMessage(""))
+			last = &messages[len(messages)-1]
+			total = 0
+		}
+		last.Embeds = append(last.Embeds, e)
+		total += e.size()
+	}
+	return messages
+}
+
+// discordItem renders one article as an embed.
+func discordItem(item output.JSONItem) discordEmbed {
+	var desc strings.Builder
+	for _, point := range item.Summary {
+		fmt.Fprintf(&desc, "• %s\n", point)
+	}
+
+	footer := fmt.Sprintf("%d点 · %s", item.Score, item.Category)
+	if item.Project != "" {
+		footer += " · " + item.Project
+		if item.Version != "" {
+			footer += " v" + item.Version
+		}
+	}
+	if item.Changed {
+		footer += " · 🔄 更新"
+	}
+
+	return discordEmbed{
+		Title:       shared.Truncate(output.ScoreEmoji(item.Score)+" "+itemTitle(item), discordMaxTitle),
+		URL:         item.URL,
+		Description: shared.Truncate(strings.TrimSuffix(desc.String(), "\n"), discordMaxDescription),
+		Color:       discordColor(item.Score),
+		Footer:      &discordFooter{Text: shared.Truncate(footer, discordMaxFooter)},
+	}
+}
+
+// discordColor matches the HTML report's score badge colors.
+func discordColor(score int) int {
+	switch {
+	case score >= 90:
+		return 0xcf222e
+	case score >= 80:
+		return 0x9a6700
+	case score >= 70:
+		return 0x0969da
+	default:
+		return 0x59636e
+	}
+}
@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestDiscordSplitsEmbeds(t *testing.T) {
+	tests := []struct {
+		name       string
+		items      int
+		summaryLen int
+		wantEmbeds []int
+	}{
+		{name: "embed limit", items: 25, summaryLen: 10, wantEmbeds: []int{10, 10, 5}},
+		{name: "character limit", items: 10, summaryLen: 1400, wantEmbeds: []int{4, 4, 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			if err := NewDiscord(srv.URL).Send(context.Background(), testDigest(tt.items, tt.summaryLen)); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if len(srv.bodies) != len(tt.wantEmbeds) {
+				t.Fatalf("messages = %d, want %d", len(srv.bodies), len(tt.wantEmbeds))
+			}
+			for i, body := range srv.bodies {
+				var msg discordMessage
+				if err := json.Unmarshal(body, &msg); err != nil {
+					t.Fatalf("message %d: %v", i+1, err)
+				}
+				if len(msg.Embeds) != tt.wantEmbeds[i] {
+					t.Errorf("message %d embeds = %d, want %d", i+1, len(msg.Embeds), tt.wantEmbeds[i])
+				}
+				total := 0
+				for _, e := range msg.Embeds {
+					total += e.size()
+				}
+				if total > discordMaxTotal {
+					t.Errorf("message %d has %d characters, limit %d", i+1, total, discordMaxTotal)
+				}
+				if (i == 0) != (msg.Content != "") {
+					t.Errorf("message %d content = %q, want the header only in the first message", i+1, msg.Content)
+				}
+			}
+		})
+	}
+}
+
+func TestDiscordDisablesMentions(t *testing.T) {
+	srv := newTestServer(t)
+	if err := NewDiscord(srv.URL).Send(context.Background(), testDigest(11, 10)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	for i, body := range srv.bodies {
+		if !bytes.Contains(body, []byte(`"allowed_mentions":{"parse":[]}`)) {
+			t.Errorf("message %d does not disable mentions: %s", i+1, body)
+		}
+	}
+}
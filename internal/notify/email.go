@@ -0,0 +1,293 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// SMTP connection security modes.
+const (
+	TLSStartTLS = "starttls" // upgrade a plain connection, typically port 587
+	TLSImplicit = "tls"      // TLS from the first byte, typically port 465
+	TLSNone     = "none"     // plain text; auth is only allowed to localhost
+)
+
+// DefaultSubject is used when EmailConfig.Subject is empty.
+const DefaultSubject = "Smart Digest {{.Date}} ({{.Count}}件)"
+
+// EmailConfig describes the SMTP server and the message envelope.
+type EmailConfig struct {
+	Host     string
+	Port     int    // defaults to 465 for TLSImplicit, 587 otherwise
+	TLS      string // TLSStartTLS (default), TLSImplicit or TLSNone
+	Username string // empty disables authentication
+	Password string
+	From     string
+	To       []string
+	Subject  string // text/template, see SubjectData
+}
+
+// SubjectData is the data available to the subject template.
+type SubjectData struct {
+	Date      string // run date, YYYY-MM-DD
+	Count     int    // articles at or above the threshold
+	Processed int
+	Failed    int
+	Threshold int
+}
+
+// Email sends the digest as a multipart/alternative message with a
+// Markdown plain-text part and an HTML part.
+type Email struct {
+	options
+	cfg     EmailConfig
+	from    *mail.Address
+	to      []*mail.Address
+	subject *template.Template
+}
+
+// NewEmail validates cfg and creates an email sink.
+func NewEmail(cfg EmailConfig, opts ...Option) (*Email, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("email: host must be specified")
+	}
+	switch cfg.TLS {
+	case "":
+		cfg.TLS = TLSStartTLS
+	case TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("email: invalid tls mode: %s (must be 'starttls', 'tls' or 'none')", cfg.TLS)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+		if cfg.TLS == TLSImplicit {
+			cfg.Port = 465
+		}
+	}
+	if cfg.Subject == "" {
+		cfg.Subject = DefaultSubject
+	}
+
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("email: invalid from address %q: %w", cfg.From, err)
+	}
+	if len(cfg.To) == 0 {
+		return nil, fmt.Errorf("email: at least one recipient must be specified")
+	}
+	var to []*mail.Address
+	for _, addr := range cfg.To {
+		a, err := mail.ParseAddress(addr)
+		if err != nil {
+			return nil, fmt.Errorf("email: invalid recipient %q: %w", addr, err)
+		}
+		to = append(to, a)
+	}
+
+	subject, err := template.New("subject").Parse(cfg.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("email: invalid subject template: %w", err)
+	}
+
+	return &Email{
+		options: newOptions("email", opts),
+		cfg:     cfg,
+		from:    from,
+		to:      to,
+		subject: subject,
+	}, nil
+}
+
+// Send renders the message once and delivers it, retrying connection
+// failures and temporary (4xx) SMTP replies.
+func (e *Email) Send(ctx context.Context, d *Digest) error {
+	msg, err := e.message(d)
+	if err != nil {
+		return err
+	}
+
+	return e.retry(ctx, func() error {
+		return e.deliver(ctx, msg)
+	}, func(err error) bool {
+		var tpErr *textproto.Error
+		if errors.As(err, &tpErr) {
+			return tpErr.Code >= 400 && tpErr.Code < 500
+		}
+		var netErr net.Error
+		return errors.As(err, &netErr)
+	}, func(error) time.Duration {
+		return 0
+	})
+}
+
+// message builds the RFC 5322 message.
+func (e *Email) message(d *Digest) ([]byte, error) {
+	run := d.Report.Run
+	var subject strings.Builder
+	if err := e.subject.Execute(&subject, SubjectData{
+		Date:      run.GeneratedAt.Local().Format("2006-01-02"),
+		Count:     run.Reported,
+		Processed: run.Processed,
+		Failed:    run.Failed,
+		Threshold: run.Threshold,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to render subject: %w", err)
+	}
+
+	var text, html bytes.Buffer
+	if err := d.Formatter.FormatMarkdown(&text, d.Results); err != nil {
+		return nil, fmt.Errorf("failed to render text part: %w", err)
+	}
+	if err := d.Formatter.FormatHTML(&html, d.Results); err != nil {
+		return nil, fmt.Errorf("failed to render HTML part: %w", err)
+	}
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+
+	to := make([]string, len(e.to))
+	for i, a := range e.to {
+		to[i] = a.String()
+	}
+	header := []struct{ key, value string }{
+		{"From", e.from.String()},
+		{"To", strings.Join(to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", strings.TrimSpace(subject.String()))},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", messageID(e.from.Address)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", `multipart/alternative; boundary="` + mw.Boundary() + `"`},
+	}
+	for _, h := range header {
+		fmt.Fprintf(&buf, "%s: %s\r\n", h.key, h.value)
+	}
+	buf.WriteString("\r\n")
+
+	// Clients show the last part they support, so HTML goes last
+	for _, part := range []struct {
+		contentType string
+		body        []byte
+	}{
+		{"text/plain; charset=utf-8", text.Bytes()},
+		{"text/html; charset=utf-8", html.Bytes()},
+	} {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(pw)
+		if _, err := qp.Write(part.body); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// deliver runs a single SMTP session.
+func (e *Email) deliver(ctx context.Context, msg []byte) error {
+	addr := net.JoinHostPort(e.cfg.Host, strconv.Itoa(e.cfg.Port))
+	dialer := &net.Dialer{Timeout: e.timeout}
+	tlsConfig := &tls.Config{ServerName: e.cfg.Host}
+
+	var conn net.Conn
+	var err error
+	if e.cfg.TLS == TLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", addr, err)
+	}
+
+	// Bound the whole session and abort it when ctx is cancelled
+	_ = conn.SetDeadline(time.Now().Add(e.timeout))
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	c, err := smtp.NewClient(conn, e.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("SMTP handshake failed: %w", err)
+	}
+	defer c.Close()
+
+	if e.cfg.TLS == TLSStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%s does not support STARTTLS", addr)
+		}
+		if err := c.StartTLS(tlsConfig); err != nil {
+			return fmt.Errorf("STARTTLS failed: %w", err)
+		}
+	}
+
+	if e.cfg.Username != "" {
+		if ok, _ := c.Extension("AUTH"); !ok {
+			return fmt.Errorf("%s does not support authentication", addr)
+		}
+		if err := c.Auth(smtp.PlainAuth("", e.cfg.Username, e.cfg.Password, e.cfg.Host)); err != nil {
+			return fmt.Errorf("authentication failed: %w", err)
+		}
+	}
+
+	if err := c.Mail(e.from.Address); err != nil {
+		return fmt.Errorf("MAIL FROM rejected: %w", err)
+	}
+	for _, a := range e.to {
+		if err := c.Rcpt(a.Address); err != nil {
+			return fmt.Errorf("recipient %s rejected: %w", a.Address, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("DATA rejected: %w", err)
+	}
+	if _, err := io.Copy(w, bytes.NewReader(msg)); err != nil {
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("message rejected: %w", err)
+	}
+
+	// The message is accepted; a failed QUIT must not trigger a resend
+	_ = c.Quit()
+	return nil
+}
+
+// messageID returns a unique Message-ID in the sender's domain.
+func messageID(from string) string {
+	domain := "smart-digest.local"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = from[i+1:]
+	}
+	var b [12]byte
+	_, _ = rand.Read(b[:])
+	return "<" + hex.EncodeToString(b[:]) + "@" + domain + ">"
+}
@@ -0,0 +1,224 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/llm"
+	"github.com/taro33333/smart-digest/internal/output"
+	"github.com/taro33333/smart-digest/internal/processor"
+)
+
+// smtpServer is a minimal SMTP stand-in. The first sessions answer MAIL
+// FROM with the given replies; later ones accept the message.
+type smtpServer struct {
+	ln net.Listener
+
+	mu       sync.Mutex
+	replies  []string
+	sessions int
+	messages []string
+}
+
+func newSMTPServer(t *testing.T, replies ...string) *smtpServer {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{ln: ln, replies: replies}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *smtpServer) serve(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	s.mu.Lock()
+	s.sessions++
+	mailReply := "250 ok"
+	if len(s.replies) > 0 {
+		mailReply, s.replies = s.replies[0], s.replies[1:]
+	}
+	s.mu.Unlock()
+
+	reply("220 localhost ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch cmd, _, _ := strings.Cut(strings.TrimSpace(line), " "); strings.ToUpper(cmd) {
+		case "EHLO":
+			reply("250-localhost")
+			reply("250 8BITMIME")
+		case "MAIL":
+			reply(mailReply)
+		case "DATA":
+			reply("354 go ahead")
+			var msg strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				msg.WriteString(strings.TrimPrefix(line, "."))
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, msg.String())
+			s.mu.Unlock()
+			reply("250 queued")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("250 ok")
+		}
+	}
+}
+
+func (s *smtpServer) newEmail(t *testing.T) *Email {
+	t.Helper()
+	addr := s.ln.Addr().(*net.TCPAddr)
+	e, err := NewEmail(EmailConfig{
+		Host: "127.0.0.1",
+		Port: addr.Port,
+		TLS:  TLSNone,
+		From: "Smart Digest <digest@example.com>",
+		To:   []string{"me@example.com"},
+	}, WithMaxAttempts(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// emailDigest returns a digest with one reported article.
+func emailDigest() *Digest {
+	results := []processor.Result{{
+		Job:      processor.Job{URL: "https://example.com/go"},
+		Article:  &fetcher.Article{URL: "https://example.com/go", Title: "Go 1.22"},
+		Analysis: &llm.AnalysisResult{Score: 90, Summary: []string{"ジェネリクスの改善"}, Category: "Go"},
+	}}
+	return NewDigest(output.New(70), results)
+}
+
+func TestEmailMessage(t *testing.T) {
+	srv := newSMTPServer(t)
+	if err := srv.newEmail(t).Send(context.Background(), emailDigest()); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	srv.mu.Lock()
+	messages := srv.messages
+	srv.mu.Unlock()
+	if len(messages) != 1 {
+		t.Fatalf("messages = %d, want 1", len(messages))
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(messages[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw := msg.Header.Get("Subject")
+	if !strings.HasPrefix(raw, "=?utf-8?q?") {
+		t.Errorf("Subject = %q, want Q-encoded", raw)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(subject, "Smart Digest ") || !strings.HasSuffix(subject, "(1件)") {
+		t.Errorf("decoded Subject = %q", subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", msg.Header.Get("Content-Type"))
+	}
+
+	var types []string
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), "ジェネリクスの改善") {
+			t.Errorf("%s part does not contain the summary", part.Header.Get("Content-Type"))
+		}
+		types = append(types, part.Header.Get("Content-Type"))
+	}
+
+	want := []string{"text/plain; charset=utf-8", "text/html; charset=utf-8"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("parts = %q, want %q", types, want)
+	}
+}
+
+func TestEmailRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		replies      []string
+		wantErr      bool
+		wantSessions int
+	}{
+		{name: "temporary failure", replies: []string{"451 try again later"}, wantSessions: 2},
+		{name: "permanent failure", replies: []string{"550 mailbox unavailable"}, wantErr: true, wantSessions: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newSMTPServer(t, tt.replies...)
+			err := srv.newEmail(t).Send(context.Background(), emailDigest())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			srv.mu.Lock()
+			defer srv.mu.Unlock()
+			if srv.sessions != tt.wantSessions {
+				t.Errorf("sessions = %d, want %d", srv.sessions, tt.wantSessions)
+			}
+			wantMessages := 1
+			if tt.wantErr {
+				wantMessages = 0
+			}
+			if len(srv.messages) != wantMessages {
+				t.Errorf("messages = %d, want %d", len(srv.messages), wantMessages)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+// Package notify delivers finished digests to chat services and webhooks.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/output"
+	"github.com/taro33333/smart-digest/internal/processor"
+	"github.com/taro33333/smart-digest/internal/shared"
+)
+
+// Sink receives the digest of a finished run.
+type Sink interface {
+	// Send delivers the digest, retrying transient failures.
+	Send(ctx context.Context, d *Digest) error
+
+	// Name identifies the sink in error messages.
+	Name() string
+}
+
+// Digest is a finished run as handed to sinks.
+type Digest struct {
+	Report output.JSONReport
+
+	// Formatter and Results render full documents for sinks such as email.
+	Formatter *output.Formatter
+	Results   []processor.Result
+}
+
+// NewDigest builds the digest for results.
+func NewDigest(f *output.Formatter, results []processor.Result) *Digest {
+	return &Digest{
+		Report:    f.Report(results),
+		Formatter: f,
+		Results:   results,
+	}
+}
+
+// SendAll delivers d to every sink and joins their errors.
+// A failing sink does not stop the others.
+func SendAll(ctx context.Context, sinks []Sink, d *Digest) error {
+	var errs []error
+	for _, s := range sinks {
+		if err := s.Send(ctx, d); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SkipEmpty wraps s so that it only sends digests with at least one item
+// above the threshold.
+func SkipEmpty(s Sink) Sink {
+	return skipEmpty{s}
+}
+
+type skipEmpty struct {
+	Sink
+}
+
+// Send forwards non-empty digests.
+func (s skipEmpty) Send(ctx context.Context, d *Digest) error {
+	if len(d.Report.Items) == 0 {
+		return nil
+	}
+	return s.Sink.Send(ctx, d)
+}
+
+// Option customizes optional sink behaviour.
+type Option func(*options)
+
+// options are the settings shared by all sinks.
+type options struct {
+	name        string
+	maxAttempts int
+	timeout     time.Duration
+}
+
+// WithName sets the name used in error messages.
+func WithName(name string) Option {
+	return func(o *options) {
+		o.name = name
+	}
+}
+
+// WithMaxAttempts sets how many attempts are made per message, including
+// the first. Retries back off from one second up to 30 seconds.
+func WithMaxAttempts(n int) Option {
+	return func(o *options) {
+		o.maxAttempts = n
+	}
+}
+
+// WithTimeout bounds a single delivery attempt.
+func WithTimeout(d time.Duration) Option {
+	return func(o *options) {
+		o.timeout = d
+	}
+}
+
+// newOptions applies opts on top of the defaults.
+func newOptions(name string, opts []Option) options {
+	o := options{
+		name:        name,
+		maxAttempts: 3,
+		timeout:     10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Name returns the sink name.
+func (o *options) Name() string {
+	return o.name
+}
+
+// Retry delays: the first retry waits about baseRetryDelay, doubling up to
+// maxRetryDelay unless the server asks for longer.
+const (
+	baseRetryDelay = time.Second
+	maxRetryDelay  = 30 * time.Second
+)
+
+// retry calls fn up to o.maxAttempts times while it fails with an error
+// that retryable accepts. retryAfter extracts a server-requested delay.
+func (o *options) retry(ctx context.Context, fn func() error, retryable func(error) bool, retryAfter func(error) time.Duration) error {
+	var lastErr error
+	for attempt := 1; attempt <= max(o.maxAttempts, 1); attempt++ {
+		if attempt > 1 && !shared.SleepContext(ctx, shared.Backoff(attempt-1, baseRetryDelay, maxRetryDelay, retryAfter(lastErr))) {
+			return lastErr
+		}
+
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil || !retryable(err) {
+			break
+		}
+	}
+	return lastErr
+}
+
+// client posts JSON payloads with retries. HTTP sinks embed one.
+type client struct {
+	options
+	url  string
+	http *http.Client
+}
+
+// newClient applies opts on top of the defaults.
+func newClient(name, url string, opts []Option) client {
+	o := newOptions(name, opts)
+	return client{
+		options: o,
+		url:     url,
+		http:    &http.Client{Timeout: o.timeout},
+	}
+}
+
+// statusError is a non-2xx response.
+type statusError struct {
+	StatusCode int
+	RetryAfter time.Duration
+	Body       string
+}
+
+// Error implements the error interface.
+func (e *statusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("HTTP %d", e.StatusCode)
+	}
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// retryable reports whether the request may succeed if repeated.
+func (e *statusError) retryable() bool {
+	return e.StatusCode == http.StatusTooManyRequests ||
+		e.StatusCode == http.StatusRequestTimeout ||
+		e.StatusCode >= 500
+}
+
+// post sends body to the sink URL, retrying rate limits, server errors
+// and transport failures. header is called for every attempt so signatures
+// can carry a fresh timestamp.
+func (c *client) post(ctx context.Context, body []byte, header func(http.Header)) error {
+	return c.retry(ctx, func() error {
+		return c.postOnce(ctx, body, header)
+	}, func(err error) bool {
+		var se *statusError
+		return !errors.As(err, &se) || se.retryable()
+	}, func(err error) time.Duration {
+		var se *statusError
+		if errors.As(err, &se) {
+			return se.RetryAfter
+		}
+		return 0
+	})
+}
+
+// postOnce makes a single request.
+func (c *client) postOnce(ctx context.Context, body []byte, header func(http.Header)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "smart-digest")
+	if header != nil {
+		header(req.Header)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Keep a short excerpt of the error body, e.g. Slack's "invalid_blocks"
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &statusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: shared.ParseRetryAfter(resp.Header),
+			Body:       strings.TrimSpace(string(excerpt)),
+		}
+	}
+	return nil
+}
+
+// itemTitle returns the display title of an item.
+func itemTitle(item output.JSONItem) string {
+	if item.Title != "" {
+		return item.Title
+	}
+	return item.URL
+}
@@ -0,0 +1,110 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/output"
+)
+
+// testServer records the requests it receives. The first responses use
+// the given status codes; later ones succeed.
+type testServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	statuses []int
+	bodies   [][]byte
+	headers  []http.Header
+}
+
+func newTestServer(t *testing.T, statuses ...int) *testServer {
+	t.Helper()
+	s := &testServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		s.mu.Lock()
+		s.bodies = append(s.bodies, body)
+		s.headers = append(s.headers, r.Header.Clone())
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		s.mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *testServer) requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.bodies)
+}
+
+// testDigest returns a digest with n items whose summary point has the given length.
+func testDigest(n, summaryLen int) *Digest {
+	report := output.JSONReport{
+		Version: output.JSONVersion,
+		Run: output.JSONRun{
+			GeneratedAt: time.Date(2024, 1, 15, 9, 0, 0, 0, time.UTC),
+			Threshold:   70,
+			Processed:   n,
+			Reported:    n,
+		},
+	}
+	for i := range n {
+		report.Items = append(report.Items, output.JSONItem{
+			URL:      fmt.Sprintf("https://example.com/%d", i),
+			Title:    fmt.Sprintf("Article %d", i),
+			Score:    80,
+			Category: "Go",
+			Summary:  []string{strings.Repeat("x", summaryLen)},
+		})
+	}
+	return &Digest{Report: report}
+}
+
+func TestPostRetries(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int
+		wantErr  bool
+		wantReqs int
+	}{
+		{name: "rate limited", statuses: []int{http.StatusTooManyRequests}, wantReqs: 2},
+		{name: "server error", statuses: []int{http.StatusServiceUnavailable}, wantReqs: 2},
+		{name: "bad request", statuses: []int{http.StatusBadRequest}, wantErr: true, wantReqs: 1},
+		{
+			name:     "attempts exhausted",
+			statuses: []int{http.StatusInternalServerError, http.StatusInternalServerError},
+			wantErr:  true,
+			wantReqs: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t, tt.statuses...)
+			sink := NewWebhook(srv.URL, "", WithMaxAttempts(2))
+
+			err := sink.Send(context.Background(), testDigest(1, 10))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := srv.requests(); got != tt.wantReqs {
+				t.Errorf("requests = %d, want %d", got, tt.wantReqs)
+			}
+		})
+	}
+}
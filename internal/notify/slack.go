@@ -0,0 +1,139 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/taro33333/smart-digest/internal/output"
+	"github.com/taro33333/smart-digest/internal/shared"
+)
+
+// Slack limits for incoming webhooks.
+const (
+	slackMaxBlocks      = 50
+	slackMaxSectionText = 3000
+	slackMaxHeaderText  = 150
+)
+
+// Slack posts the digest to a Slack incoming webhook using Block Kit.
+// Reports that do not fit in one message are split across several.
+type Slack struct {
+	client
+}
+
+// NewSlack creates a sink for the given incoming webhook URL.
+func NewSlack(webhookURL string, opts ...Option) *Slack {
+	return &Slack{client: newClient("slack", webhookURL, opts)}
+}
+
+// slackMessage is an incoming webhook payload.
+type slackMessage struct {
+	Text   string       `json:"text"` // notification fallback
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type     string      `json:"type"`
+	Text     *slackText  `json:"text,omitempty"`
+	Elements []slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"` // "plain_text" or "mrkdwn"
+	Text string `json:"text"`
+}
+
+// Send posts the report as one or more messages.
+func (s *Slack) Send(ctx context.Context, d *Digest) error {
+	for i, msg := range slackMessages(d.Report) {
+		body, err := json.Marshal(msg)
+		if err != nil {
+			return fmt.Errorf("failed to encode message: %w", err)
+		}
+		if err := s.post(ctx, body, nil); err != nil {
+			return fmt.Errorf("message %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// slackMessages renders the report into messages of at most slackMaxBlocks.
+func slackMessages(report output.JSONReport) []slackMessage {
+	title := fmt.Sprintf("Smart Digest %s", report.Run.GeneratedAt.Local().Format("2006-01-02"))
+	fallback := fmt.Sprintf("%s: %d件", title, report.Run.Reported)
+
+	blocks := []slackBlock{
+		{Type: "header", Text: &slackText{Type: "plain_text", Text: shared.Truncate(title, slackMaxHeaderText)}},
+		{Type: "context", Elements: []slackText{{Type: "mrkdwn", Text: summaryLine(report.Run)}}},
+		{Type: "divider"},
+	}
+
+	if len(report.Items) == 0 {
+		blocks = append(blocks, slackSection("該当する記事はありませんでした。"))
+	}
+	for _, item := range report.Items {
+		blocks = append(blocks, slackSection(slackItem(item)))
+	}
+
+	if len(report.Errors) > 0 {
+		var b strings.Builder
+		fmt.Fprintf(&b, "*⚠️ エラー (%d件)*", len(report.Errors))
+		for _, e := range report.Errors {
+			fmt.Fprintf(&b, "\n• %s: `%s`", slackEscape(e.URL), slackEscape(e.Error))
+		}
+		blocks = append(blocks, slackSection(b.String()))
+	}
+
+	var messages []slackMessage
+	for len(blocks) > 0 {
+		n := min(len(blocks), slackMaxBlocks)
+		messages = append(messages, slackMessage{Text: fallback, Blocks: blocks[:n]})
+		blocks = blocks[n:]
+	}
+	return messages
+}
+
+// slackItem renders one article as mrkdwn.
+func slackItem(item output.JSONItem) string {
+	var b strings.Builder
+	link := strings.ReplaceAll(item.URL, "|", "%7C")
+	fmt.Fprintf(&b, "*<%s|%s>*\n", slackEscape(link), slackEscape(strings.ReplaceAll(itemTitle(item), "|", "¦")))
+	fmt.Fprintf(&b, "%s %d点 · `%s`", output.ScoreEmoji(item.Score), item.Score, slackEscape(item.Category))
+	if item.Project != "" {
+		fmt.Fprintf(&b, " · %s", slackEscape(item.Project))
+		if item.Version != "" {
+			fmt.Fprintf(&b, " v%s", slackEscape(item.Version))
+		}
+	}
+	if item.Changed {
+		b.WriteString(" · 🔄 更新")
+	}
+	for _, point := range item.Summary {
+		fmt.Fprintf(&b, "\n• %s", slackEscape(point))
+	}
+	return b.String()
+}
+
+// slackSection wraps mrkdwn text in a section block, truncated to Slack's limit.
+func slackSection(text string) slackBlock {
+	return slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: shared.Truncate(text, slackMaxSectionText)}}
+}
+
+// slackEscape escapes the control characters of Slack's mrkdwn.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
+
+// summaryLine is the run statistics line shared by the chat sinks.
+func summaryLine(run output.JSONRun) string {
+	line := fmt.Sprintf("閾値: %d点以上 | 処理数: %d件 | 該当: %d件", run.Threshold, run.Processed, run.Reported)
+	if run.Skipped > 0 {
+		line += fmt.Sprintf(" | 既出: %d件", run.Skipped)
+	}
+	if run.Failed > 0 {
+		line += fmt.Sprintf(" | エラー: %d件", run.Failed)
+	}
+	return line
+}
@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestSlackSplitsBlocks(t *testing.T) {
+	tests := []struct {
+		name       string
+		items      int
+		wantBlocks []int
+	}{
+		// Header, context and divider come first
+		{name: "one message", items: 47, wantBlocks: []int{50}},
+		{name: "two messages", items: 48, wantBlocks: []int{50, 1}},
+		{name: "three messages", items: 120, wantBlocks: []int{50, 50, 23}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := newTestServer(t)
+			if err := NewSlack(srv.URL).Send(context.Background(), testDigest(tt.items, 10)); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if len(srv.bodies) != len(tt.wantBlocks) {
+				t.Fatalf("messages = %d, want %d", len(srv.bodies), len(tt.wantBlocks))
+			}
+			for i, body := range srv.bodies {
+				var msg slackMessage
+				if err := json.Unmarshal(body, &msg); err != nil {
+					t.Fatalf("message %d: %v", i+1, err)
+				}
+				if len(msg.Blocks) != tt.wantBlocks[i] {
+					t.Errorf("message %d blocks = %d, want %d", i+1, len(msg.Blocks), tt.wantBlocks[i])
+				}
+				if msg.Text == "" {
+					t.Errorf("message %d has no fallback text", i+1)
+				}
+			}
+		})
+	}
+}
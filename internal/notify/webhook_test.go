@@ -0,0 +1,58 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/taro33333/smart-digest/internal/output"
+)
+
+func TestWebhookSignature(t *testing.T) {
+	srv := newTestServer(t, http.StatusServiceUnavailable)
+	secret := "s3cret"
+	if err := NewWebhook(srv.URL, secret).Send(context.Background(), testDigest(2, 10)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// The retry must be signed as well
+	if len(srv.bodies) != 2 {
+		t.Fatalf("requests = %d, want 2", len(srv.bodies))
+	}
+	for i, body := range srv.bodies {
+		ts := srv.headers[i].Get(HeaderTimestamp)
+		if ts == "" {
+			t.Fatalf("request %d has no %s header", i+1, HeaderTimestamp)
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(ts + "." + string(body)))
+		want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+		if got := srv.headers[i].Get(HeaderSignature); got != want {
+			t.Errorf("request %d signature = %q, want %q", i+1, got, want)
+		}
+
+		var report output.JSONReport
+		if err := json.Unmarshal(body, &report); err != nil {
+			t.Fatalf("request %d body: %v", i+1, err)
+		}
+		if len(report.Items) != 2 {
+			t.Errorf("request %d items = %d, want 2", i+1, len(report.Items))
+		}
+	}
+}
+
+func TestWebhookUnsigned(t *testing.T) {
+	srv := newTestServer(t)
+	if err := NewWebhook(srv.URL, "").Send(context.Background(), testDigest(1, 10)); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if got := srv.headers[0].Get(HeaderSignature); got != "" {
+		t.Errorf("signature = %q, want none without a secret", got)
+	}
+}
@@ -5,67 +5,112 @@ import (
 	"fmt"
 	"io"
 	"sort"
-	"strings"
 	"time"
 
+	"github.com/taro33333/smart-digest/internal/history"
 	"github.com/taro33333/smart-digest/internal/processor"
 )
 
 // Formatter handles result formatting.
 type Formatter struct {
 	threshold int
+	provider  string
+	model     string
+	groupBy   GroupBy
+	interests []string
+}
+
+// Option customizes optional Formatter behaviour.
+type Option func(*Formatter)
+
+// WithRunInfo records the configured provider and model in run metadata.
+func WithRunInfo(provider, model string) Option {
+	return func(f *Formatter) {
+		f.provider = provider
+		f.model = model
+	}
 }
 
 // New creates a new Formatter with the given threshold.
-func New(threshold int) *Formatter {
-	return &Formatter{
+func New(threshold int, opts ...Option) *Formatter {
+	f := &Formatter{
 		threshold: threshold,
 	}
-}
+	for _, opt := range opts {
+		opt(f)
+	}
 
-// FormatMarkdown generates Markdown output from results.
-func (f *Formatter) FormatMarkdown(w io.Writer, results []processor.Result) error {
-	// Filter and sort results
-	var filtered []processor.Result
-	var errors []processor.Result
+	return f
+}
 
+// partition splits results into reported items (sorted by score descending)
+// and errors, and counts the articles skipped as already reported.
+func (f *Formatter) partition(results []processor.Result) (reported, errors []processor.Result, skipped int) {
 	for _, r := range results {
 		if r.Error != nil {
 			errors = append(errors, r)
 			continue
 		}
+		if r.Skipped {
+			skipped++
+			continue
+		}
 		if r.Analysis != nil && r.Analysis.Score >= f.threshold {
-			filtered = append(filtered, r)
+			reported = append(reported, r)
 		}
 	}
 
 	// Sort by score descending
-	sort.Slice(filtered, func(i, j int) bool {
-		return filtered[i].Analysis.Score > filtered[j].Analysis.Score
+	sort.SliceStable(reported, func(i, j int) bool {
+		return reported[i].Analysis.Score > reported[j].Analysis.Score
 	})
 
+	return reported, errors, skipped
+}
+
+// FormatMarkdown generates Markdown output from results.
+func (f *Formatter) FormatMarkdown(w io.Writer, results []processor.Result) error {
+	filtered, errors, skipped := f.partition(results)
+
 	// Generate header
 	fmt.Fprintf(w, "# Smart Digest Report\n\n")
 	fmt.Fprintf(w, "_Generated: %s_\n\n", time.Now().Format("2006-01-02 15:04"))
-	fmt.Fprintf(w, "**閾値:** %d点以上 | **処理数:** %d件 | **該当:** %d件\n\n",
+	fmt.Fprintf(w, "**閾値:** %d点以上 | **処理数:** %d件 | **該当:** %d件",
 		f.threshold, len(results), len(filtered))
+	if skipped > 0 {
+		fmt.Fprintf(w, " | **既出:** %d件", skipped)
+	}
+	fmt.Fprintf(w, "\n\n")
 
 	if len(filtered) == 0 {
 		fmt.Fprintf(w, "> 該当する記事はありませんでした。\n\n")
 	}
 
 	// Generate entries
-	fmt.Fprintf(w, "---\n\n")
-	for i, r := range filtered {
-		f.formatEntry(w, i+1, r)
+	if f.groupBy != GroupNone && len(filtered) > 0 {
+		f.formatGroups(w, filtered)
+	} else {
+		fmt.Fprintf(w, "---\n\n")
+		for i, r := range filtered {
+			f.formatEntry(w, "##", i+1, r)
+		}
 	}
 
 	// Error summary
 	if len(errors) > 0 {
 		fmt.Fprintf(w, "## ⚠️ エラー (%d件)\n\n", len(errors))
 		for _, r := range errors {
-			fmt.Fprintf(w, "- **%s**\n  - `%s`\n",
-				r.Job.URL, r.Error.Error())
+			marker := ""
+			switch errorKind(r.Error) {
+			case ErrorKindBlocked:
+				marker = " 🚫"
+			case ErrorKindRobots:
+				marker = " 🤖"
+			case ErrorKindCanceled:
+				marker = " ⏸️"
+			}
+			fmt.Fprintf(w, "- **%s**%s\n  - `%s`\n",
+				r.Job.URL, marker, r.Error.Error())
 		}
 		fmt.Fprintf(w, "\n")
 	}
@@ -73,20 +118,53 @@ func (f *Formatter) FormatMarkdown(w io.Writer, results []processor.Result) erro
 	return nil
 }
 
-// formatEntry formats a single result entry.
-func (f *Formatter) formatEntry(w io.Writer, num int, r processor.Result) {
+// formatGroups writes a table of contents followed by one section per group.
+// Entries are numbered within their section.
+func (f *Formatter) formatGroups(w io.Writer, reported []processor.Result) {
+	groups := f.groups(reported)
+
+	fmt.Fprintf(w, "## 目次\n\n")
+	for _, g := range groups {
+		fmt.Fprintf(w, "- [%s (%d件)](#%s)\n", g.Name, len(g.Results), g.Anchor)
+	}
+	fmt.Fprintf(w, "\n---\n\n")
+
+	for _, g := range groups {
+		fmt.Fprintf(w, "<a id=\"%s\"></a>\n\n", g.Anchor)
+		fmt.Fprintf(w, "## %s (%d件)\n\n", g.Name, len(g.Results))
+		for i, r := range g.Results {
+			f.formatEntry(w, "###", i+1, r)
+		}
+	}
+}
+
+// formatEntry formats a single result entry with the given heading level.
+func (f *Formatter) formatEntry(w io.Writer, heading string, num int, r processor.Result) {
 	title := r.Article.Title
+	if title == "" {
+		title = r.Job.Title
+	}
 	if title == "" {
 		title = r.Job.URL
 	}
 
 	// Score emoji
-	scoreEmoji := getScoreEmoji(r.Analysis.Score)
+	scoreEmoji := ScoreEmoji(r.Analysis.Score)
 
-	fmt.Fprintf(w, "## %d. %s %s\n\n", num, scoreEmoji, title)
+	fmt.Fprintf(w, "%s %d. %s %s\n\n", heading, num, scoreEmoji, title)
 	fmt.Fprintf(w, "**URL:** %s\n\n", r.Job.URL)
-	fmt.Fprintf(w, "**スコア:** %d/100 | **カテゴリ:** `%s`\n\n",
+	fmt.Fprintf(w, "**スコア:** %d/100 | **カテゴリ:** `%s`",
 		r.Analysis.Score, r.Analysis.Category)
+	if r.Provider != "" {
+		fmt.Fprintf(w, " | **LLM:** %s", r.Provider)
+	}
+	fmt.Fprintf(w, "\n\n")
+
+	// Changed since an earlier run
+	if r.Seen == history.StatusChanged && r.Previous != nil {
+		fmt.Fprintf(w, "**🔄 更新:** 前回 (%s, %d点) から内容が変更されています\n\n",
+			r.Previous.LastSeen.Local().Format("2006-01-02"), r.Previous.Score)
+	}
 
 	// Version info if available
 	if r.Job.Project != "" || r.Job.Version != "" {
@@ -97,49 +175,25 @@ func (f *Formatter) formatEntry(w io.Writer, num int, r processor.Result) {
 		}
 	}
 
+	// Feed info if available
+	if r.Job.Source != "" {
+		if !r.Job.Published.IsZero() {
+			fmt.Fprintf(w, "**フィード:** %s (%s)\n\n", r.Job.Source, r.Job.Published.Local().Format("2006-01-02"))
+		} else {
+			fmt.Fprintf(w, "**フィード:** %s\n\n", r.Job.Source)
+		}
+	}
+
 	// Summary
-	fmt.Fprintf(w, "### 要約\n\n")
+	fmt.Fprintf(w, "%s# 要約\n\n", heading)
 	for _, point := range r.Analysis.Summary {
 		fmt.Fprintf(w, "- %s\n", point)
 	}
 	fmt.Fprintf(w, "\n---\n\n")
 }
 
-// FormatJSON generates JSON output from results.
-func (f *Formatter) FormatJSON(w io.Writer, results []processor.Result) error {
-	var filtered []processor.Result
-	for _, r := range results {
-		if r.Error == nil && r.Analysis != nil && r.Analysis.Score >= f.threshold {
-			filtered = append(filtered, r)
-		}
-	}
-
-	sort.Slice(filtered, func(i, j int) bool {
-		return filtered[i].Analysis.Score > filtered[j].Analysis.Score
-	})
-
-	fmt.Fprintf(w, "[\n")
-	for i, r := range filtered {
-		summary := strings.Join(r.Analysis.Summary, " / ")
-		fmt.Fprintf(w, "  {\n")
-		fmt.Fprintf(w, "    \"url\": %q,\n", r.Job.URL)
-		fmt.Fprintf(w, "    \"title\": %q,\n", r.Article.Title)
-		fmt.Fprintf(w, "    \"score\": %d,\n", r.Analysis.Score)
-		fmt.Fprintf(w, "    \"category\": %q,\n", r.Analysis.Category)
-		fmt.Fprintf(w, "    \"summary\": %q\n", summary)
-		if i < len(filtered)-1 {
-			fmt.Fprintf(w, "  },\n")
-		} else {
-			fmt.Fprintf(w, "  }\n")
-		}
-	}
-	fmt.Fprintf(w, "]\n")
-
-	return nil
-}
-
-// getScoreEmoji returns an emoji based on score.
-func getScoreEmoji(score int) string {
+// ScoreEmoji returns the marker shown next to an article with the given score.
+func ScoreEmoji(score int) string {
 	switch {
 	case score >= 90:
 		return "🔥"
@@ -0,0 +1,160 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/taro33333/smart-digest/internal/processor"
+)
+
+// GroupBy selects how reported items are split into sections.
+type GroupBy string
+
+const (
+	GroupNone     GroupBy = ""
+	GroupCategory GroupBy = "category"
+	GroupProject  GroupBy = "project"
+	GroupInterest GroupBy = "interest"
+	GroupSource   GroupBy = "source"
+)
+
+// otherGroup collects items without a value for the grouping key.
+const otherGroup = "その他"
+
+// ParseGroupBy validates a --group-by value. An empty string disables grouping.
+func ParseGroupBy(s string) (GroupBy, error) {
+	switch by := GroupBy(s); by {
+	case GroupNone, GroupCategory, GroupProject, GroupInterest, GroupSource:
+		return by, nil
+	default:
+		return GroupNone, fmt.Errorf("invalid group-by: %s (must be 'category', 'project', 'interest' or 'source')", s)
+	}
+}
+
+// WithGroupBy renders Markdown and HTML reports in sections with a table of
+// contents.
+func WithGroupBy(by GroupBy) Option {
+	return func(f *Formatter) {
+		f.groupBy = by
+	}
+}
+
+// WithInterests sets the configured interests used by GroupInterest.
+func WithInterests(interests []string) Option {
+	return func(f *Formatter) {
+		f.interests = interests
+	}
+}
+
+// group is a report section.
+type group struct {
+	Name    string
+	Anchor  string
+	Results []processor.Result
+}
+
+// groups splits reported results (already sorted by score) into sections.
+// Sections are ordered by item count, then by their best score; items keep
+// their score order. Items without a key go to a trailing "その他" section.
+func (f *Formatter) groups(reported []processor.Result) []group {
+	var groups []group
+	index := make(map[string]int)
+	for _, r := range reported {
+		name := f.groupKey(r)
+		if name == "" {
+			name = otherGroup
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, group{Name: name})
+		}
+		groups[i].Results = append(groups[i].Results, r)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		a, b := groups[i], groups[j]
+		if (a.Name == otherGroup) != (b.Name == otherGroup) {
+			return b.Name == otherGroup
+		}
+		if len(a.Results) != len(b.Results) {
+			return len(a.Results) > len(b.Results)
+		}
+		return a.Results[0].Analysis.Score > b.Results[0].Analysis.Score
+	})
+
+	used := make(map[string]bool)
+	for i := range groups {
+		groups[i].Anchor = anchor(groups[i].Name, used)
+	}
+	return groups
+}
+
+// groupKey returns the section name for r, or "" when it has none.
+func (f *Formatter) groupKey(r processor.Result) string {
+	switch f.groupBy {
+	case GroupCategory:
+		return r.Analysis.Category
+	case GroupProject:
+		return r.Job.Project
+	case GroupSource:
+		return r.Job.Source
+	case GroupInterest:
+		return f.matchInterest(r)
+	default:
+		return ""
+	}
+}
+
+// matchInterest returns the configured interest that best describes r: an
+// exact category match first, then the first interest mentioned in the
+// category, title or summary.
+func (f *Formatter) matchInterest(r processor.Result) string {
+	for _, interest := range f.interests {
+		if strings.EqualFold(interest, r.Analysis.Category) {
+			return interest
+		}
+	}
+
+	title := r.Job.Title
+	if r.Article != nil && r.Article.Title != "" {
+		title = r.Article.Title
+	}
+	text := strings.ToLower(strings.Join(append([]string{r.Analysis.Category, title}, r.Analysis.Summary...), "\n"))
+	for _, interest := range f.interests {
+		if interest != "" && strings.Contains(text, strings.ToLower(interest)) {
+			return interest
+		}
+	}
+	return ""
+}
+
+// anchor returns a unique HTML id for a section name.
+func anchor(name string, used map[string]bool) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+
+	id := "group-" + strings.TrimSuffix(b.String(), "-")
+	if id == "group-" {
+		id = "group"
+	}
+	base := id
+	for n := 2; used[id]; n++ {
+		id = base + "-" + strconv.Itoa(n)
+	}
+	used[id] = true
+	return id
+}
@@ -0,0 +1,86 @@
+package output
+
+import (
+	"embed"
+	"html/template"
+	"io"
+	"sort"
+
+	"github.com/taro33333/smart-digest/internal/processor"
+)
+
+//go:embed templates
+var builtinTemplates embed.FS
+
+// htmlTemplate renders the self-contained HTML report. html/template escapes
+// all article-derived text and neutralizes unsafe link schemes.
+var htmlTemplate = template.Must(template.New("report.html.tmpl").Funcs(template.FuncMap{
+	"scoreEmoji": ScoreEmoji,
+	"scoreClass": scoreClass,
+}).ParseFS(builtinTemplates, "templates/report.html.tmpl"))
+
+// htmlReport is the data passed to the HTML template.
+type htmlReport struct {
+	JSONReport
+	Categories []categoryCount
+	Groups     []htmlGroup // set when grouping is enabled
+}
+
+// htmlGroup is a report section with its table of contents anchor.
+type htmlGroup struct {
+	Name   string
+	Anchor string
+	Items  []JSONItem
+}
+
+// categoryCount is a filter chip in the HTML report.
+type categoryCount struct {
+	Name  string
+	Count int
+}
+
+// FormatHTML generates a single HTML file with inline CSS and script.
+func (f *Formatter) FormatHTML(w io.Writer, results []processor.Result) error {
+	report := htmlReport{JSONReport: f.Report(results)}
+
+	if f.groupBy != GroupNone {
+		reported, _, _ := f.partition(results)
+		for _, g := range f.groups(reported) {
+			hg := htmlGroup{Name: g.Name, Anchor: g.Anchor}
+			for _, r := range g.Results {
+				hg.Items = append(hg.Items, jsonItem(r))
+			}
+			report.Groups = append(report.Groups, hg)
+		}
+	}
+
+	counts := make(map[string]int)
+	for _, item := range report.Items {
+		if counts[item.Category] == 0 {
+			report.Categories = append(report.Categories, categoryCount{Name: item.Category})
+		}
+		counts[item.Category]++
+	}
+	for i := range report.Categories {
+		report.Categories[i].Count = counts[report.Categories[i].Name]
+	}
+	sort.SliceStable(report.Categories, func(i, j int) bool {
+		return report.Categories[i].Count > report.Categories[j].Count
+	})
+
+	return htmlTemplate.Execute(w, report)
+}
+
+// scoreClass returns the CSS class for a score badge, matching ScoreEmoji.
+func scoreClass(score int) string {
+	switch {
+	case score >= 90:
+		return "score-hot"
+	case score >= 80:
+		return "score-star"
+	case score >= 70:
+		return "score-pin"
+	default:
+		return "score-low"
+	}
+}
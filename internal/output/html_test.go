@@ -0,0 +1,85 @@
+package output
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/llm"
+	"github.com/taro33333/smart-digest/internal/processor"
+)
+
+// htmlResults returns reported articles in two categories.
+func htmlResults() []processor.Result {
+	var results []processor.Result
+	for _, a := range []struct {
+		url, category string
+		score         int
+	}{
+		{"https://example.com/go", "Go", 90},
+		{"https://example.com/k8s", "Kubernetes", 85},
+		{"https://example.com/generics", "Go", 80},
+	} {
+		results = append(results, processor.Result{
+			Job:      processor.Job{URL: a.url},
+			Article:  &fetcher.Article{URL: a.url, Title: a.url},
+			Analysis: &llm.AnalysisResult{Score: a.score, Summary: []string{"summary"}, Category: a.category},
+		})
+	}
+	return results
+}
+
+func TestFormatHTML(t *testing.T) {
+	tests := []struct {
+		name    string
+		groupBy GroupBy
+		want    []string
+		wantNot []string
+	}{
+		{
+			name: "flat",
+			want: []string{
+				`<nav class="chips">`,
+				`data-filter="">すべて<span class="count">3</span>`,
+				`data-filter="Go">Go<span class="count">2</span>`,
+				`data-filter="Kubernetes">Kubernetes<span class="count">1</span>`,
+			},
+			wantNot: []string{`<nav class="toc">`, `<section class="group"`},
+		},
+		{
+			name:    "grouped",
+			groupBy: GroupCategory,
+			want: []string{
+				`<nav class="toc">`,
+				`<section class="group"`,
+				`<nav class="chips">`,
+				`data-filter="Go">Go<span class="count">2</span>`,
+				`data-filter="Kubernetes">Kubernetes<span class="count">1</span>`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			if err := New(70, WithGroupBy(tt.groupBy)).FormatHTML(&b, htmlResults()); err != nil {
+				t.Fatalf("FormatHTML() error = %v", err)
+			}
+			html := b.String()
+
+			for _, s := range tt.want {
+				if !strings.Contains(html, s) {
+					t.Errorf("report does not contain %q", s)
+				}
+			}
+			for _, s := range tt.wantNot {
+				if strings.Contains(html, s) {
+					t.Errorf("report contains %q", s)
+				}
+			}
+			if got := strings.Count(html, `<article class="item"`); got != 3 {
+				t.Errorf("items = %d, want 3", got)
+			}
+		})
+	}
+}
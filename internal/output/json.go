@@ -0,0 +1,159 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/history"
+	"github.com/taro33333/smart-digest/internal/processor"
+)
+
+// JSONVersion is incremented on incompatible changes to the JSON report.
+const JSONVersion = 1
+
+// JSONReport is the document written by FormatJSON.
+type JSONReport struct {
+	Version int         `json:"version"`
+	Run     JSONRun     `json:"run"`
+	Items   []JSONItem  `json:"items"`
+	Errors  []JSONError `json:"errors"`
+}
+
+// JSONRun describes the run that produced a report.
+type JSONRun struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Threshold   int       `json:"threshold"`
+	Provider    string    `json:"provider,omitempty"`
+	Model       string    `json:"model,omitempty"`
+	Processed   int       `json:"processed"`
+	Reported    int       `json:"reported"`
+	Skipped     int       `json:"skipped"` // already reported in an earlier run
+	Failed      int       `json:"failed"`
+}
+
+// JSONItem is an article that passed the threshold.
+type JSONItem struct {
+	URL       string    `json:"url"`
+	Title     string    `json:"title"`
+	Score     int       `json:"score"`
+	Category  string    `json:"category"`
+	Summary   []string  `json:"summary"`
+	Excerpt   string    `json:"excerpt,omitempty"`
+	Project   string    `json:"project,omitempty"`
+	Version   string    `json:"version,omitempty"`
+	Source    string    `json:"source,omitempty"` // feed name
+	Published time.Time `json:"published,omitzero"`
+	Provider  string    `json:"provider,omitempty"`
+	Model     string    `json:"model,omitempty"`
+	Cached    bool      `json:"cached"`
+	Changed   bool      `json:"changed"` // content differs from an earlier run
+}
+
+// Error kinds in JSONError.Kind.
+const (
+	ErrorKindBlocked  = "blocked"  // refused by the fetcher's network policy
+	ErrorKindRobots   = "robots"   // disallowed by the site's robots.txt
+	ErrorKindCanceled = "canceled" // not processed because the run was interrupted
+)
+
+// JSONError is an article that could not be processed.
+type JSONError struct {
+	URL     string `json:"url"`
+	Project string `json:"project,omitempty"`
+	Version string `json:"version,omitempty"`
+	Error   string `json:"error"`
+	Kind    string `json:"kind,omitempty"`
+}
+
+// FormatJSON writes results as a versioned JSON document.
+func (f *Formatter) FormatJSON(w io.Writer, results []processor.Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	// Keep URLs with query strings readable
+	enc.SetEscapeHTML(false)
+	return enc.Encode(f.Report(results))
+}
+
+// Report builds the structured report shared by the JSON, HTML and
+// template formats and by notification sinks.
+func (f *Formatter) Report(results []processor.Result) JSONReport {
+	reported, errors, skipped := f.partition(results)
+
+	report := JSONReport{
+		Version: JSONVersion,
+		Run: JSONRun{
+			GeneratedAt: time.Now(),
+			Threshold:   f.threshold,
+			Provider:    f.provider,
+			Model:       f.model,
+			Processed:   len(results),
+			Reported:    len(reported),
+			Skipped:     skipped,
+			Failed:      len(errors),
+		},
+		Items:  make([]JSONItem, 0, len(reported)),
+		Errors: make([]JSONError, 0, len(errors)),
+	}
+
+	for _, r := range reported {
+		report.Items = append(report.Items, jsonItem(r))
+	}
+	for _, r := range errors {
+		report.Errors = append(report.Errors, JSONError{
+			URL:     r.Job.URL,
+			Project: r.Job.Project,
+			Version: r.Job.Version,
+			Error:   r.Error.Error(),
+			Kind:    errorKind(r.Error),
+		})
+	}
+
+	return report
+}
+
+// errorKind classifies err for reports, or returns "" for ordinary failures.
+func errorKind(err error) string {
+	if errors.Is(err, fetcher.ErrBlocked) {
+		return ErrorKindBlocked
+	}
+	if errors.Is(err, fetcher.ErrDisallowed) {
+		return ErrorKindRobots
+	}
+	if errors.Is(err, context.Canceled) {
+		return ErrorKindCanceled
+	}
+	return ""
+}
+
+// jsonItem converts an analyzed result.
+func jsonItem(r processor.Result) JSONItem {
+	item := JSONItem{
+		URL:       r.Job.URL,
+		Title:     r.Job.Title,
+		Score:     r.Analysis.Score,
+		Category:  r.Analysis.Category,
+		Summary:   r.Analysis.Summary,
+		Project:   r.Job.Project,
+		Version:   r.Job.Version,
+		Source:    r.Job.Source,
+		Published: r.Job.Published,
+		Provider:  r.Analysis.Provider,
+		Model:     r.Analysis.Model,
+		Cached:    r.Cached,
+		Changed:   r.Seen == history.StatusChanged,
+	}
+	if r.Article != nil {
+		if r.Article.Title != "" {
+			item.Title = r.Article.Title
+		}
+		item.Excerpt = r.Article.Excerpt
+	}
+	if item.Summary == nil {
+		item.Summary = []string{}
+	}
+	return item
+}
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/taro33333/smart-digest/internal/processor"
+)
+
+// Line statuses in JSON Lines output.
+const (
+	LineReported       = "reported"
+	LineBelowThreshold = "below_threshold"
+	LineSkipped        = "skipped" // already reported in an earlier run
+	LineError          = "error"
+)
+
+// JSONLine is one result in JSON Lines output. Analysis fields are only
+// present for reported and below-threshold results.
+type JSONLine struct {
+	Status  string `json:"status"`
+	URL     string `json:"url"`
+	Project string `json:"project,omitempty"`
+	Version string `json:"version,omitempty"`
+	*JSONItem
+	Error string `json:"error,omitempty"`
+	Kind  string `json:"kind,omitempty"` // error kind, see JSONError
+}
+
+// WriteJSONLine writes a single result as one line of JSON. Unlike the other
+// formats it includes every result, so callers can stream each one as soon
+// as it completes.
+func (f *Formatter) WriteJSONLine(w io.Writer, r processor.Result) error {
+	line := JSONLine{
+		URL:     r.Job.URL,
+		Project: r.Job.Project,
+		Version: r.Job.Version,
+	}
+
+	switch {
+	case r.Error != nil:
+		line.Status = LineError
+		line.Error = r.Error.Error()
+		line.Kind = errorKind(r.Error)
+	case r.Skipped || r.Analysis == nil:
+		line.Status = LineSkipped
+	default:
+		line.Status = LineReported
+		if r.Analysis.Score < f.threshold {
+			line.Status = LineBelowThreshold
+		}
+		item := jsonItem(r)
+		line.JSONItem = &item
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	return enc.Encode(line)
+}
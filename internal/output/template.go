@@ -0,0 +1,103 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/processor"
+	"github.com/taro33333/smart-digest/internal/shared"
+)
+
+// TemplateData is the data model passed to user templates. Item and error
+// fields are the same as in the JSON report.
+type TemplateData struct {
+	Run            JSONRun
+	Items          []JSONItem            // at or above the threshold, highest score first
+	BelowThreshold []JSONItem            // analyzed but below the threshold, highest score first
+	Errors         []JSONError           // articles that could not be processed
+	ByCategory     map[string][]JSONItem // Items grouped by category
+	Categories     []string              // ByCategory keys, most items first
+}
+
+// TemplateFuncs are the helper functions available to user templates.
+// Arguments are ordered so they work at the end of a pipeline:
+//
+//	{{.Run.GeneratedAt | date "2006-01-02"}}
+//	{{.Title | truncate 60}}
+//	{{.Summary | join " / "}}
+//	{{scoreEmoji .Score}}
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"date":       formatDate,
+		"truncate":   truncate,
+		"join":       join,
+		"scoreEmoji": ScoreEmoji,
+	}
+}
+
+// LoadTemplate parses a user template file with TemplateFuncs.
+func LoadTemplate(path string) (*template.Template, error) {
+	tmpl, err := template.New(filepath.Base(path)).Funcs(TemplateFuncs()).ParseFiles(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load output template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// FormatTemplate renders results with a user template.
+func (f *Formatter) FormatTemplate(w io.Writer, tmpl *template.Template, results []processor.Result) error {
+	report := f.Report(results)
+	data := TemplateData{
+		Run:        report.Run,
+		Items:      report.Items,
+		Errors:     report.Errors,
+		ByCategory: make(map[string][]JSONItem),
+	}
+
+	for _, r := range results {
+		if r.Error == nil && r.Analysis != nil && r.Analysis.Score < f.threshold {
+			data.BelowThreshold = append(data.BelowThreshold, jsonItem(r))
+		}
+	}
+	sort.SliceStable(data.BelowThreshold, func(i, j int) bool {
+		return data.BelowThreshold[i].Score > data.BelowThreshold[j].Score
+	})
+
+	for _, item := range data.Items {
+		if _, ok := data.ByCategory[item.Category]; !ok {
+			data.Categories = append(data.Categories, item.Category)
+		}
+		data.ByCategory[item.Category] = append(data.ByCategory[item.Category], item)
+	}
+	sort.SliceStable(data.Categories, func(i, j int) bool {
+		return len(data.ByCategory[data.Categories[i]]) > len(data.ByCategory[data.Categories[j]])
+	})
+
+	if err := tmpl.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render output template: %w", err)
+	}
+	return nil
+}
+
+// formatDate formats t in local time; zero times render as "".
+func formatDate(layout string, t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Local().Format(layout)
+}
+
+// truncate is shared.Truncate with the length first, for use in pipelines.
+func truncate(n int, s string) string {
+	return shared.Truncate(s, n)
+}
+
+// join concatenates elems with sep.
+func join(sep string, elems []string) string {
+	return strings.Join(elems, sep)
+}
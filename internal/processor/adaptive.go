@@ -0,0 +1,261 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/llm"
+	"github.com/taro33333/smart-digest/internal/shared"
+)
+
+// LimitState describes the LLM limits in effect after an adaptive adjustment.
+type LimitState struct {
+	Concurrency int     // LLM calls allowed in flight
+	Rate        float64 // LLM calls per second, 0 when unlimited
+	Reason      string  // what triggered the change
+}
+
+// LimitReporter is called whenever the adaptive limiter changes the limits.
+type LimitReporter func(LimitState)
+
+// WithAdaptiveLimits lets the LLM concurrency and request rate adapt
+// AIMD-style: rate-limit errors halve both, overloaded or slow responses
+// (above latencyThreshold, zero disables) shrink the concurrency, and
+// successful calls grow them back towards max_workers and the configured
+// rate. The rate never drops below minRate.
+func WithAdaptiveLimits(minRate float64, latencyThreshold time.Duration) Option {
+	return func(p *Processor) {
+		p.adaptive = &adaptiveLimiter{
+			minRate:          minRate,
+			latencyThreshold: latencyThreshold,
+		}
+	}
+}
+
+// WithLimitReporter sets a callback for changes made by the adaptive limiter.
+func WithLimitReporter(fn LimitReporter) Option {
+	return func(p *Processor) {
+		p.limitReporter = fn
+	}
+}
+
+const (
+	// rateRecoverySteps is how many successful calls bring the rate from
+	// zero back to its maximum.
+	rateRecoverySteps = 20
+	// latencyDecrease shrinks the concurrency after a slow or overloaded response.
+	latencyDecrease = 0.75
+)
+
+// adaptiveLimiter gates LLM calls by an adjustable concurrency and drives
+// the request rate of the LLM token bucket. It also pauses calls while the
+// provider's x-ratelimit-* headers say the quota is used up.
+type adaptiveLimiter struct {
+	bucket           *tokenBucket // may be nil when the rate is unlimited
+	maxConcurrency   int
+	maxRate          float64
+	minRate          float64
+	latencyThreshold time.Duration
+	report           LimitReporter
+
+	mu           sync.Mutex
+	changed      chan struct{} // closed and replaced when a slot frees up or limits change
+	concurrency  float64
+	inFlight     int
+	rate         float64
+	ceiling      float64   // rate derived from the provider's requests-per-minute limit
+	lastDecrease time.Time // calls started before this do not decrease again
+
+	pausedUntil     time.Time
+	requestsLeft    int // -1 when unknown
+	requestsResetAt time.Time
+	tokensLeft      int // -1 when unknown
+	tokensResetAt   time.Time
+}
+
+// init sets the upper bounds once the processor's limits are known.
+func (l *adaptiveLimiter) init(bucket *tokenBucket, maxConcurrency int, maxRate float64, report LimitReporter) {
+	l.bucket = bucket
+	l.maxConcurrency = maxConcurrency
+	l.maxRate = maxRate
+	l.minRate = min(l.minRate, maxRate)
+	l.report = report
+	l.changed = make(chan struct{})
+	l.concurrency = float64(maxConcurrency)
+	l.rate = maxRate
+	l.requestsLeft = -1
+	l.tokensLeft = -1
+}
+
+// acquire waits for a free slot and for any quota pause to end. Every
+// successful acquire must be followed by release.
+func (l *adaptiveLimiter) acquire(ctx context.Context, tokens int) error {
+	for {
+		l.mu.Lock()
+		now := time.Now()
+		wait := l.pauseLocked(now, tokens)
+		if wait == 0 && l.inFlight < max(int(l.concurrency), 1) {
+			l.inFlight++
+			if l.requestsLeft > 0 {
+				l.requestsLeft--
+			}
+			if l.tokensLeft > 0 {
+				l.tokensLeft = max(l.tokensLeft-tokens, 0)
+			}
+			l.mu.Unlock()
+			return nil
+		}
+		changed := l.changed
+		l.mu.Unlock()
+
+		if wait > 0 {
+			if !shared.SleepContext(ctx, wait) {
+				return ctx.Err()
+			}
+			continue
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// pauseLocked returns how long calls must wait for the provider's quota.
+func (l *adaptiveLimiter) pauseLocked(now time.Time, tokens int) time.Duration {
+	until := l.pausedUntil
+	if l.requestsLeft == 0 && l.requestsResetAt.After(until) {
+		until = l.requestsResetAt
+	}
+	if l.tokensLeft >= 0 && l.tokensLeft < tokens && l.tokensResetAt.After(until) {
+		until = l.tokensResetAt
+	}
+	if until.After(now) {
+		return until.Sub(now)
+	}
+	// The windows have been replenished; forget the stale counts
+	if l.requestsLeft == 0 {
+		l.requestsLeft = -1
+	}
+	if l.tokensLeft >= 0 && l.tokensLeft < tokens {
+		l.tokensLeft = -1
+	}
+	return 0
+}
+
+// release frees the slot of a call started at start and adapts the limits
+// to its outcome. Errors other than rate limits and overloads are neutral.
+func (l *adaptiveLimiter) release(start time.Time, err error) {
+	latency := time.Since(start)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.inFlight--
+	defer l.signalLocked()
+
+	switch {
+	case errors.Is(err, llm.ErrRateLimited):
+		if retryAfter := llm.RetryAfter(err); retryAfter > 0 {
+			if until := time.Now().Add(retryAfter); until.After(l.pausedUntil) {
+				l.pausedUntil = until
+			}
+		}
+		l.decreaseLocked(start, 0.5, 0.5, "rate limited")
+	case errors.Is(err, llm.ErrTransient):
+		l.decreaseLocked(start, latencyDecrease, 1, "provider overloaded")
+	case err == nil && l.latencyThreshold > 0 && latency > l.latencyThreshold:
+		l.decreaseLocked(start, latencyDecrease, 1, "slow response ("+latency.Round(time.Second).String()+")")
+	case err == nil:
+		l.increaseLocked()
+	}
+}
+
+// decreaseLocked scales the concurrency and rate down once per congestion
+// event: calls that started before the previous decrease are ignored.
+func (l *adaptiveLimiter) decreaseLocked(start time.Time, concurrencyFactor, rateFactor float64, reason string) {
+	if start.Before(l.lastDecrease) {
+		return
+	}
+	l.lastDecrease = time.Now()
+	l.concurrency = max(l.concurrency*concurrencyFactor, 1)
+	l.setRateLocked(max(l.rate*rateFactor, l.minRate))
+	l.reportLocked(reason)
+}
+
+// increaseLocked grows the concurrency by one per round of successful calls
+// and the rate by a fixed step, up to their maximums.
+func (l *adaptiveLimiter) increaseLocked() {
+	before := l.capacityLocked()
+	rateBefore := l.rate
+
+	l.concurrency = min(l.concurrency+1/l.concurrency, float64(l.maxConcurrency))
+	l.setRateLocked(min(l.rate+l.maxRate/rateRecoverySteps, l.rateCapLocked()))
+
+	if l.capacityLocked() != before || (l.rate == l.rateCapLocked() && rateBefore < l.rate) {
+		l.reportLocked("recovering")
+	}
+}
+
+// observe applies the quotas reported in a provider's response headers.
+func (l *adaptiveLimiter) observe(limits llm.RateLimits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer l.signalLocked()
+
+	now := time.Now()
+	if r := limits.Requests; r != nil {
+		l.requestsLeft = r.Remaining
+		l.requestsResetAt = now.Add(r.Reset)
+		if r.Limit > 0 {
+			ceiling := float64(r.Limit) / 60
+			if ceiling != l.ceiling {
+				l.ceiling = ceiling
+				if l.rate > l.rateCapLocked() {
+					l.setRateLocked(l.rateCapLocked())
+					l.reportLocked(fmt.Sprintf("provider limit %d req/min", r.Limit))
+				}
+			}
+		}
+	}
+	if t := limits.Tokens; t != nil {
+		l.tokensLeft = t.Remaining
+		l.tokensResetAt = now.Add(t.Reset)
+	}
+}
+
+// rateCapLocked is the highest rate allowed: the configured one, lowered to
+// the provider's requests-per-minute limit when it reports one.
+func (l *adaptiveLimiter) rateCapLocked() float64 {
+	if l.ceiling > 0 && l.ceiling < l.maxRate {
+		return l.ceiling
+	}
+	return l.maxRate
+}
+
+func (l *adaptiveLimiter) setRateLocked(rate float64) {
+	if l.bucket == nil {
+		return
+	}
+	l.rate = rate
+	l.bucket.setRate(rate)
+}
+
+func (l *adaptiveLimiter) capacityLocked() int {
+	return max(int(l.concurrency), 1)
+}
+
+func (l *adaptiveLimiter) reportLocked(reason string) {
+	if l.report != nil {
+		l.report(LimitState{Concurrency: l.capacityLocked(), Rate: l.rate, Reason: reason})
+	}
+}
+
+// signalLocked wakes callers waiting in acquire.
+func (l *adaptiveLimiter) signalLocked() {
+	close(l.changed)
+	l.changed = make(chan struct{})
+}
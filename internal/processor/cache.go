@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/taro33333/smart-digest/internal/cache"
+	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/llm"
+)
+
+// WithCache enables the on-disk cache. model identifies the provider
+// configuration in analysis keys; refresh skips lookups but still stores
+// fresh results.
+func WithCache(store *cache.Cache, model string, refresh bool) Option {
+	return func(p *Processor) {
+		p.cache = store
+		p.cacheModel = model
+		p.cacheRefresh = refresh
+	}
+}
+
+// fetchArticle returns the cached article for url or fetches and caches it.
+// The network policy is checked first so the cache cannot serve pages the
+// policy now refuses.
+func (p *Processor) fetchArticle(ctx context.Context, url string) (*fetcher.Article, error) {
+	if err := p.fetcher.CheckURL(url); err != nil {
+		return nil, err
+	}
+
+	if p.cache != nil && !p.cacheRefresh {
+		if article, ok := p.cache.GetArticle(url); ok {
+			return article, nil
+		}
+	}
+
+	if err := p.fetchLimit.wait(ctx, 1); err != nil {
+		return nil, err
+	}
+	article, err := p.fetcher.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.cache != nil {
+		// The cache is best-effort; a failed write only costs a refetch
+		_ = p.cache.PutArticle(article)
+	}
+	return article, nil
+}
+
+// analysisKey builds the cache key for an article's analysis.
+func (p *Processor) analysisKey(job Job, article *fetcher.Article) cache.AnalysisKey {
+	return cache.AnalysisKey{
+		URL:         job.URL,
+		ContentHash: article.ContentHash(),
+		Model:       p.cacheModel,
+		// Interests change the score, so they are part of the prompt identity
+		PromptVersion: p.prompts.Version() + "|" + strings.Join(p.interests, ","),
+	}
+}
+
+// analyzeCached returns a cached analysis when available, otherwise analyzes
+// the article and stores the result. The bool reports a cache hit.
+func (p *Processor) analyzeCached(ctx context.Context, job Job, article *fetcher.Article) (*llm.AnalysisResult, bool, error) {
+	if p.cache == nil {
+		analysis, err := p.analyzeArticle(ctx, job, article)
+		return analysis, false, err
+	}
+
+	key := p.analysisKey(job, article)
+	if !p.cacheRefresh {
+		if analysis, ok := p.cache.GetAnalysis(key); ok {
+			return analysis, true, nil
+		}
+	}
+
+	analysis, err := p.analyzeArticle(ctx, job, article)
+	if err != nil {
+		return nil, false, err
+	}
+
+	_ = p.cache.PutAnalysis(key, analysis)
+	return analysis, false, nil
+}
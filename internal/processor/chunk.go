@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/llm"
+)
+
+// analyzeArticle analyzes an article, switching to map-reduce summarization
+// when the content exceeds the chunk budget: each chunk is analyzed on its
+// own, then a final analysis is produced from the chunk summaries.
+func (p *Processor) analyzeArticle(ctx context.Context, job Job, article *fetcher.Article) (*llm.AnalysisResult, error) {
+	req := llm.Request{
+		Content:   article.Content,
+		Interests: p.interests,
+		Title:     article.Title,
+		Project:   job.Project,
+		Version:   job.Version,
+	}
+
+	if p.chunkChars <= 0 || len(article.Content) <= p.chunkChars {
+		return p.analyzeWithRetry(ctx, req)
+	}
+
+	chunks := llm.SplitChunks(article.Content, p.chunkChars)
+	if len(chunks) == 1 {
+		return p.analyzeWithRetry(ctx, req)
+	}
+
+	partials := make([]*llm.AnalysisResult, 0, len(chunks))
+	for i, chunk := range chunks {
+		chunkReq := req
+		chunkReq.Content = p.prompts.ChunkContent(i+1, len(chunks), chunk)
+		partial, err := p.analyzeWithRetry(ctx, chunkReq)
+		if err != nil {
+			return nil, fmt.Errorf("chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		partials = append(partials, partial)
+	}
+
+	reduceReq := req
+	reduceReq.Content = p.prompts.ReduceContent(article.Title, partials)
+	return p.analyzeWithRetry(ctx, reduceReq)
+}
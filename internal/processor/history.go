@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"context"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/history"
+)
+
+// WithHistory records every analyzed article in store. Articles scoring at
+// least threshold are marked as reported; with onlyNew, articles already
+// reported with unchanged content are skipped before analysis.
+func WithHistory(store *history.Store, threshold int, onlyNew bool) Option {
+	return func(p *Processor) {
+		p.history = store
+		p.historyThreshold = threshold
+		p.onlyNew = onlyNew
+	}
+}
+
+type thresholdKey struct{}
+
+// WithThreshold returns a context that makes Process mark articles scoring
+// at least threshold as reported, instead of the threshold given to
+// WithHistory. Use it when a single run reports with its own threshold.
+func WithThreshold(ctx context.Context, threshold int) context.Context {
+	return context.WithValue(ctx, thresholdKey{}, threshold)
+}
+
+// reportThreshold returns the threshold for history records made under ctx.
+func (p *Processor) reportThreshold(ctx context.Context) int {
+	if threshold, ok := ctx.Value(thresholdKey{}).(int); ok {
+		return threshold
+	}
+	return p.historyThreshold
+}
+
+// checkHistory fills in the article's history status and reports whether
+// the job should be skipped.
+func (p *Processor) checkHistory(result *Result, article *fetcher.Article) bool {
+	if p.history == nil {
+		return false
+	}
+
+	hash := article.ContentHash()
+	result.Seen, result.Previous = p.history.Lookup(result.Job.URL, hash)
+
+	if !p.onlyNew || !p.history.AlreadyReported(result.Job.URL, hash) {
+		return false
+	}
+
+	// Keep the record alive for retention without touching the report date
+	p.history.Observe(result.Job.URL, hash, result.Previous.Score, false, time.Now())
+	result.Skipped = true
+	return true
+}
+
+// recordHistory stores the analysis outcome for the article, marking it
+// as reported when it scores at least threshold.
+func (p *Processor) recordHistory(result *Result, threshold int) {
+	if p.history == nil || result.Analysis == nil {
+		return
+	}
+
+	score := result.Analysis.Score
+	p.history.Observe(result.Job.URL, result.Article.ContentHash(), score, score >= threshold, time.Now())
+}
+
+// RestoreHistory re-applies the history updates of results finished by an
+// earlier, interrupted run (restored from its journal), since that run did
+// not save its history.
+func (p *Processor) RestoreHistory(results []Result) {
+	if p.history == nil {
+		return
+	}
+	for i := range results {
+		r := &results[i]
+		switch {
+		case r.Skipped && r.Article != nil && r.Previous != nil:
+			p.history.Observe(r.Job.URL, r.Article.ContentHash(), r.Previous.Score, false, time.Now())
+		case r.Article != nil:
+			p.recordHistory(r, p.historyThreshold)
+		}
+	}
+}
@@ -1,4 +1,6 @@
-// Package processor handles concurrent URL processing with worker pools.
+// Package processor handles concurrent URL processing as a two-stage
+// pipeline: a fetch pool downloads and extracts articles and an analyze pool
+// sends them to the LLM, each with its own workers and rate limits.
 package processor
 
 import (
@@ -7,7 +9,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/taro33333/smart-digest/internal/cache"
 	"github.com/taro33333/smart-digest/internal/fetcher"
+	"github.com/taro33333/smart-digest/internal/history"
 	"github.com/taro33333/smart-digest/internal/llm"
 )
 
@@ -16,6 +20,11 @@ type Job struct {
 	URL     string
 	Project string
 	Version string
+
+	// Set for jobs expanded from feeds
+	Title     string
+	Published time.Time
+	Source    string // feed name
 }
 
 // Result represents the processed output for a URL.
@@ -24,95 +33,179 @@ type Result struct {
 	Article  *fetcher.Article
 	Analysis *llm.AnalysisResult
 	Error    error
+
+	// Provider names the backend that produced Analysis, e.g. "Anthropic (claude-haiku-4-5)".
+	Provider string
+
+	// Cached is true when Analysis was served from the on-disk cache.
+	Cached bool
+
+	// Seen is the article's status relative to earlier runs and Previous its
+	// record from before this run. Both are only set when history is enabled.
+	Seen     history.Status
+	Previous *history.Record
+
+	// Skipped is true when the article was already reported with the same
+	// content and only new articles were requested. Analysis is nil.
+	Skipped bool
 }
 
+// DefaultFetchWorkers is the fetch pool size used when none is configured.
+const DefaultFetchWorkers = 8
+
 // Processor handles concurrent URL processing.
 type Processor struct {
-	fetcher       *fetcher.Fetcher
-	llmProvider   llm.Provider
-	interests     []string
-	maxWorkers    int
-	rateLimitTick time.Duration
+	fetcher      *fetcher.Fetcher
+	llmProvider  llm.Provider
+	interests    []string
+	retry        RetryPolicy
+	chunkChars   int
+	prompts      *llm.Prompts
+	cache        *cache.Cache
+	cacheModel   string
+	cacheRefresh bool
+
+	fetchWorkers int
+	fetchRate    float64
+	fetchBurst   int
+	fetchLimit   *tokenBucket
+
+	analyzeWorkers  int
+	llmRate         float64
+	llmBurst        int
+	tokensPerMinute int
+	llmRequests     *tokenBucket
+	llmTokens       *tokenBucket
+	adaptive        *adaptiveLimiter
+	limitReporter   LimitReporter
+
+	history          *history.Store
+	historyThreshold int
+	onlyNew          bool
+}
+
+// Option customizes optional Processor behaviour.
+type Option func(*Processor)
+
+// WithRetryPolicy sets how failed LLM calls are retried.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(p *Processor) {
+		p.retry = policy
+	}
 }
 
-// New creates a new Processor with the given configuration.
-func New(f *fetcher.Fetcher, provider llm.Provider, interests []string, maxWorkers int, rateLimit float64) *Processor {
-	// Calculate rate limit interval
-	// e.g., rateLimit=0.05 means 1 request per 20 seconds (3 RPM)
-	var tickDuration time.Duration
-	if rateLimit >= 1 {
-		tickDuration = time.Second / time.Duration(rateLimit)
-	} else {
-		// For rates < 1 per second, calculate interval in seconds
-		tickDuration = time.Duration(float64(time.Second) / rateLimit)
-	}
-
-	return &Processor{
-		fetcher:       f,
-		llmProvider:   provider,
-		interests:     interests,
-		maxWorkers:    maxWorkers,
-		rateLimitTick: tickDuration,
+// WithChunking enables map-reduce summarization for articles longer than
+// chunkChars bytes. Zero disables chunking.
+func WithChunking(chunkChars int) Option {
+	return func(p *Processor) {
+		p.chunkChars = chunkChars
 	}
 }
 
+// WithPrompts sets the prompts used for chunked summarization and JSON
+// repair. It should match the prompts the provider was created with.
+func WithPrompts(prompts *llm.Prompts) Option {
+	return func(p *Processor) {
+		p.prompts = prompts
+	}
+}
+
+// WithFetchLimits sets the fetch stage's worker count and its request rate
+// (requests per second with the given burst). A zero rate leaves fetching
+// bounded only by the workers and the fetcher's per-host limits.
+func WithFetchLimits(workers int, rate float64, burst int) Option {
+	return func(p *Processor) {
+		if workers > 0 {
+			p.fetchWorkers = workers
+		}
+		p.fetchRate = rate
+		p.fetchBurst = burst
+	}
+}
+
+// WithLLMLimits sets the burst of the LLM request rate and a budget of
+// estimated tokens per minute. Zero tokensPerMinute disables the budget.
+func WithLLMLimits(burst, tokensPerMinute int) Option {
+	return func(p *Processor) {
+		p.llmBurst = burst
+		p.tokensPerMinute = tokensPerMinute
+	}
+}
+
+// New creates a new Processor with the given configuration. maxWorkers and
+// rateLimit (LLM requests per second, e.g. 0.05 for 3 RPM) apply to the
+// analyze stage; the fetch stage is configured with WithFetchLimits.
+func New(f *fetcher.Fetcher, provider llm.Provider, interests []string, maxWorkers int, rateLimit float64, opts ...Option) *Processor {
+	p := &Processor{
+		fetcher:        f,
+		llmProvider:    provider,
+		interests:      interests,
+		retry:          DefaultRetryPolicy(),
+		prompts:        llm.DefaultPrompts(),
+		fetchWorkers:   DefaultFetchWorkers,
+		analyzeWorkers: max(maxWorkers, 1),
+		llmRate:        rateLimit,
+		llmBurst:       1,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.fetchLimit = newTokenBucket(p.fetchRate, p.fetchBurst)
+	p.llmRequests = newTokenBucket(p.llmRate, p.llmBurst)
+	if p.tokensPerMinute > 0 {
+		p.llmTokens = newTokenBucket(float64(p.tokensPerMinute)/60, p.tokensPerMinute)
+	}
+	if p.adaptive != nil {
+		p.adaptive.init(p.llmRequests, p.analyzeWorkers, p.llmRate, p.limitReporter)
+	}
+
+	return p
+}
+
 // ProcessCallback is called for each processed result (for progress updates).
 type ProcessCallback func(completed, total int, result *Result)
 
-// Process handles multiple URLs concurrently and returns results.
+// Process handles multiple URLs concurrently and returns one result per
+// job, including after ctx is cancelled. Jobs are fetched by the fetch pool
+// and handed to the analyze pool, so slow sites do not hold LLM workers and
+// the LLM rate limit does not slow fetching.
 func (p *Processor) Process(ctx context.Context, jobs []Job, callback ProcessCallback) []Result {
 	if len(jobs) == 0 {
 		return nil
 	}
 
-	// Create channels
 	jobChan := make(chan Job, len(jobs))
-	resultChan := make(chan Result, len(jobs))
-
-	// Rate limiter ticker
-	rateLimiter := time.NewTicker(p.rateLimitTick)
-	defer rateLimiter.Stop()
+	for _, job := range jobs {
+		jobChan <- job
+	}
+	close(jobChan)
 
-	// Create a rate-limited job channel
-	rateLimitedJobs := make(chan Job, p.maxWorkers)
+	// A small buffer keeps fetching from running far ahead of a slow LLM
+	fetched := make(chan Result, p.analyzeWorkers)
+	resultChan := make(chan Result, len(jobs))
 
-	// Start rate limiter goroutine
+	var fetchWG sync.WaitGroup
+	for range p.fetchWorkers {
+		fetchWG.Go(func() {
+			p.fetchWorker(ctx, jobChan, fetched, resultChan)
+		})
+	}
 	go func() {
-		defer close(rateLimitedJobs)
-		for job := range jobChan {
-			select {
-			case <-ctx.Done():
-				return
-			case <-rateLimiter.C:
-				rateLimitedJobs <- job
-			}
-		}
+		fetchWG.Wait()
+		close(fetched)
 	}()
 
-	// Start workers
-	var wg sync.WaitGroup
-	for i := 0; i < p.maxWorkers; i++ {
-		wg.Go(func() {
-			p.worker(ctx, rateLimitedJobs, resultChan)
+	var analyzeWG sync.WaitGroup
+	for range p.analyzeWorkers {
+		analyzeWG.Go(func() {
+			p.analyzeWorker(ctx, fetched, resultChan)
 		})
 	}
 
-	// Send jobs
+	// The analyze pool finishes after the fetch pool closed its input
 	go func() {
-		for _, job := range jobs {
-			select {
-			case jobChan <- job:
-			case <-ctx.Done():
-				close(jobChan)
-				return
-			}
-		}
-		close(jobChan)
-	}()
-
-	// Close results when all workers are done
-	go func() {
-		wg.Wait()
+		analyzeWG.Wait()
 		close(resultChan)
 	}()
 
@@ -130,43 +223,60 @@ func (p *Processor) Process(ctx context.Context, jobs []Job, callback ProcessCal
 	return results
 }
 
-// worker processes jobs from the channel.
-func (p *Processor) worker(ctx context.Context, jobs <-chan Job, results chan<- Result) {
+// fetchWorker fetches and extracts articles. Failed and skipped jobs are
+// final; the rest are passed on for analysis. Once ctx is cancelled the
+// remaining jobs are returned with ctx's error, so every job has a result.
+func (p *Processor) fetchWorker(ctx context.Context, jobs <-chan Job, fetched, results chan<- Result) {
 	for job := range jobs {
-		select {
-		case <-ctx.Done():
-			results <- Result{
-				Job:   job,
-				Error: ctx.Err(),
-			}
-			return
-		default:
+		if ctx.Err() != nil {
+			results <- Result{Job: job, Error: ctx.Err()}
+			continue
 		}
 
-		result := p.processJob(ctx, job)
-		results <- result
+		result := Result{Job: job}
+		article, err := p.fetchArticle(ctx, job.URL)
+		if err != nil {
+			result.Error = fmt.Errorf("fetch failed: %w", err)
+			results <- result
+			continue
+		}
+		result.Article = article
+
+		if p.checkHistory(&result, article) {
+			results <- result
+			continue
+		}
+		fetched <- result
 	}
 }
 
-// processJob handles a single URL processing.
-func (p *Processor) processJob(ctx context.Context, job Job) Result {
-	result := Result{Job: job}
-
-	// Step 1: Fetch and extract content
-	article, err := p.fetcher.Fetch(ctx, job.URL)
-	if err != nil {
-		result.Error = fmt.Errorf("fetch failed: %w", err)
-		return result
+// analyzeWorker analyzes fetched articles. It drains its input even after
+// ctx is cancelled so the fetch pool never blocks.
+func (p *Processor) analyzeWorker(ctx context.Context, fetched <-chan Result, results chan<- Result) {
+	for result := range fetched {
+		if ctx.Err() != nil {
+			result.Error = ctx.Err()
+			results <- result
+			continue
+		}
+		results <- p.analyzeJob(ctx, result)
 	}
-	result.Article = article
+}
 
-	// Step 2: Analyze with LLM
-	analysis, err := p.llmProvider.Analyze(ctx, article.Content, p.interests)
+// analyzeJob completes a fetched result with the LLM analysis.
+func (p *Processor) analyzeJob(ctx context.Context, result Result) Result {
+	analysis, cached, err := p.analyzeCached(ctx, result.Job, result.Article)
 	if err != nil {
 		result.Error = fmt.Errorf("analysis failed: %w", err)
 		return result
 	}
 	result.Analysis = analysis
+	result.Cached = cached
+	result.Provider = analysis.Provider
+	if analysis.Model != "" {
+		result.Provider = fmt.Sprintf("%s (%s)", analysis.Provider, analysis.Model)
+	}
+	p.recordHistory(&result, p.reportThreshold(ctx))
 
 	return result
 }
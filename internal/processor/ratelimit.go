@@ -0,0 +1,127 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/taro33333/smart-digest/internal/shared"
+)
+
+// tokenBucket is a rate limiter that refills rate tokens per second up to
+// burst. Callers reserve tokens in arrival order, so a large request delays
+// the ones behind it instead of being starved by small ones. A nil bucket
+// never blocks.
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// newTokenBucket returns a full bucket, or nil when rate is not positive.
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	b := float64(max(burst, 1))
+	return &tokenBucket{rate: rate, burst: b, tokens: b, last: time.Now()}
+}
+
+// wait takes n tokens, blocking until the bucket has refilled enough.
+// Requests larger than the burst are allowed and wait proportionally longer.
+func (b *tokenBucket) wait(ctx context.Context, n float64) error {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.tokens -= n
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	if !shared.SleepContext(ctx, delay) {
+		// Give the reservation back so cancelled callers do not slow others
+		b.mu.Lock()
+		b.tokens += n
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+	return nil
+}
+
+// setRate changes the refill rate, keeping the tokens earned at the old one.
+func (b *tokenBucket) setRate(rate float64) {
+	if b == nil || rate <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	b.tokens = min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+	b.rate = rate
+}
+
+// promptOverhead approximates the tokens of the prompt template and the
+// model's JSON reply that come on top of the article content.
+const promptOverhead = 800
+
+// estimateTokens roughly counts the tokens of s: about four ASCII
+// characters per token, and one token per other character (CJK text).
+func estimateTokens(s string) int {
+	ascii, other := 0, 0
+	for i := 0; i < len(s); {
+		if s[i] < utf8.RuneSelf {
+			ascii++
+			i++
+			continue
+		}
+		_, size := utf8.DecodeRuneInString(s[i:])
+		other++
+		i += size
+	}
+	return ascii/4 + other
+}
+
+// acquireLLM blocks until an LLM call with content fits the adaptive
+// concurrency, the request rate and the tokens-per-minute budget. The
+// returned func must be called with the call's outcome.
+func (p *Processor) acquireLLM(ctx context.Context, content string) (func(error), error) {
+	tokens := estimateTokens(content) + promptOverhead
+	if p.adaptive != nil {
+		if err := p.adaptive.acquire(ctx, tokens); err != nil {
+			return nil, err
+		}
+	}
+
+	err := p.llmRequests.wait(ctx, 1)
+	if err == nil {
+		err = p.llmTokens.wait(ctx, float64(tokens))
+	}
+	if err != nil {
+		if p.adaptive != nil {
+			p.adaptive.release(time.Now(), err)
+		}
+		return nil, err
+	}
+
+	start := time.Now()
+	return func(err error) {
+		if p.adaptive != nil {
+			p.adaptive.release(start, err)
+		}
+	}, nil
+}
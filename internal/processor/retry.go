@@ -0,0 +1,91 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/llm"
+	"github.com/taro33333/smart-digest/internal/shared"
+)
+
+// RetryPolicy controls how failed LLM calls are retried per job.
+type RetryPolicy struct {
+	MaxAttempts int           // total attempts including the first, 1 disables retries
+	BaseDelay   time.Duration // delay before the second attempt
+	MaxDelay    time.Duration // upper bound for a single backoff
+}
+
+// DefaultRetryPolicy returns the policy used when none is configured.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   time.Second,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// analyzeWithRetry calls the LLM provider, retrying classified failures.
+// An invalid-response failure is followed by a single immediate re-prompt
+// asking the model to fix its JSON before falling back to fresh attempts.
+func (p *Processor) analyzeWithRetry(ctx context.Context, req llm.Request) (*llm.AnalysisResult, error) {
+	maxAttempts := max(p.retry.MaxAttempts, 1)
+	repaired := false
+	if p.adaptive != nil {
+		ctx = llm.WithRateLimitObserver(ctx, p.adaptive.observe)
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var analysis *llm.AnalysisResult
+		var done func(error)
+		var err error
+
+		var llmErr *llm.Error
+		if !repaired && errors.As(lastErr, &llmErr) && errors.Is(lastErr, llm.ErrInvalidResponse) && llmErr.Response != "" {
+			repaired = true
+			if done, err = p.acquireLLM(ctx, req.Content+llmErr.Response); err != nil {
+				return nil, err
+			}
+			analysis, err = llm.Repair(ctx, p.llmProvider, p.prompts, req, llmErr.Response)
+			done(err)
+		} else {
+			if attempt > 1 && !shared.SleepContext(ctx, shared.Backoff(attempt-1, p.retry.BaseDelay, p.retry.MaxDelay, llm.RetryAfter(lastErr))) {
+				return nil, interrupted(ctx, lastErr)
+			}
+			if done, err = p.acquireLLM(ctx, req.Content); err != nil {
+				return nil, err
+			}
+			analysis, err = p.llmProvider.Analyze(ctx, req)
+			done(err)
+		}
+
+		if err == nil {
+			return analysis, nil
+		}
+		lastErr = err
+
+		if ctx.Err() != nil {
+			return nil, interrupted(ctx, lastErr)
+		}
+		if !llm.IsRetryable(err) {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
+
+// interrupted returns the error of a job cut short by ctx. It always
+// matches ctx.Err(), so the job counts as cancelled rather than failed and
+// is retried on resume; lastErr is kept as the cause.
+func interrupted(ctx context.Context, lastErr error) error {
+	switch {
+	case lastErr == nil:
+		return ctx.Err()
+	case errors.Is(lastErr, ctx.Err()):
+		return lastErr
+	default:
+		return errors.Join(ctx.Err(), lastErr)
+	}
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/taro33333/smart-digest/internal/input"
+	"github.com/taro33333/smart-digest/internal/output"
+)
+
+// Handler returns the HTTP API:
+//
+//	POST /v1/jobs               submit URLs (same JSON / JSON Lines format as stdin)
+//	GET  /v1/jobs/{id}          poll job status
+//	GET  /v1/jobs/{id}/events   stream status updates as server-sent events
+//	GET  /v1/jobs/{id}/result   fetch the report (?format=markdown|json|html, ?group_by=)
+//	GET  /healthz               liveness check
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/jobs", s.handleSubmit)
+	mux.HandleFunc("GET /v1/jobs/{id}", s.handleStatus)
+	mux.HandleFunc("GET /v1/jobs/{id}/events", s.handleEvents)
+	mux.HandleFunc("GET /v1/jobs/{id}/result", s.handleResult)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return mux
+}
+
+// handleSubmit parses the body into jobs and queues them.
+// An optional ?threshold= overrides the configured threshold for this job.
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	threshold := s.threshold
+	if v := r.URL.Query().Get("threshold"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 100 {
+			writeError(w, http.StatusBadRequest, "threshold must be between 0 and 100")
+			return
+		}
+		threshold = n
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, s.maxBodyBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	jobs, err := input.New().ParseStdin(bytes.NewReader(body))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(jobs) == 0 {
+		writeError(w, http.StatusBadRequest, "no URLs provided")
+		return
+	}
+	if len(jobs) > s.maxURLs {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("too many URLs: %d (max %d)", len(jobs), s.maxURLs))
+		return
+	}
+	for _, job := range jobs {
+		if u, err := url.Parse(job.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid URL: %s", job.URL))
+			return
+		}
+	}
+
+	j, err := s.submit(jobs, threshold)
+	if err != nil {
+		if errors.Is(err, errQueueFull) {
+			w.Header().Set("Retry-After", "30")
+		}
+		writeError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+
+	status, _ := j.snapshot()
+	w.Header().Set("Location", "/v1/jobs/"+j.id)
+	writeJSON(w, http.StatusAccepted, status)
+}
+
+// handleStatus returns the job's current status.
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.lookup(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	status, _ := j.snapshot()
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleEvents streams a "status" event on every update until the job
+// finishes or the client disconnects.
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.lookup(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+
+	for {
+		status, changed := j.snapshot()
+		data, err := json.Marshal(status)
+		if err != nil {
+			return
+		}
+		if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+			return
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		if status.Status.terminal() {
+			return
+		}
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-changed:
+		}
+	}
+}
+
+// handleResult renders a finished job with the markdown, JSON or HTML formatter.
+// An optional ?group_by= splits the markdown and HTML reports into sections.
+func (s *Server) handleResult(w http.ResponseWriter, r *http.Request) {
+	j, ok := s.lookup(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, "job not found")
+		return
+	}
+
+	results, done := j.result()
+	if !done {
+		status, _ := j.snapshot()
+		writeJSON(w, http.StatusConflict, status)
+		return
+	}
+
+	groupBy, err := output.ParseGroupBy(r.URL.Query().Get("group_by"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	formatter := output.New(j.threshold, append(s.formatOpts, output.WithGroupBy(groupBy))...)
+	var buf bytes.Buffer
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		err = formatter.FormatMarkdown(&buf, results)
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		err = formatter.FormatJSON(&buf, results)
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		err = formatter.FormatHTML(&buf, results)
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("unknown format: %s (must be 'markdown', 'json' or 'html')", format))
+		return
+	}
+	if err != nil {
+		w.Header().Del("Content-Type")
+		writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	_, _ = buf.WriteTo(w)
+}
+
+// writeJSON writes v as a JSON response.
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an {"error": msg} response.
+func writeError(w http.ResponseWriter, code int, msg string) {
+	writeJSON(w, code, map[string]string{"error": msg})
+}
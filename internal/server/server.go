@@ -0,0 +1,363 @@
+// Package server exposes the digest pipeline over HTTP.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/taro33333/smart-digest/internal/history"
+	"github.com/taro33333/smart-digest/internal/output"
+	"github.com/taro33333/smart-digest/internal/processor"
+)
+
+// Status is the lifecycle state of a submitted job.
+type Status string
+
+const (
+	StatusQueued  Status = "queued"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// Submission errors reported by the job queue.
+var (
+	errQueueFull    = errors.New("job queue is full")
+	errShuttingDown = errors.New("server is shutting down")
+)
+
+// Server queues submitted jobs and runs them strictly one at a time on a
+// shared Processor, so the configured worker count and rate limit apply
+// across all clients. A job waits until every job submitted before it has
+// finished.
+type Server struct {
+	proc      *processor.Processor
+	threshold int
+	history   *history.Store
+
+	formatOpts []output.Option
+
+	maxBodyBytes int64
+	maxURLs      int
+	jobTTL       time.Duration
+
+	queue    chan *job
+	stopping chan struct{} // closed by Shutdown
+	done     chan struct{} // closed when Run returns
+
+	mu        sync.Mutex
+	jobs      map[string]*job
+	closed    bool
+	cancelRun context.CancelFunc
+}
+
+// Option customizes optional Server behaviour.
+type Option func(*Server)
+
+// WithLimits caps the request body size and the number of URLs per job.
+func WithLimits(maxBodyBytes int64, maxURLs int) Option {
+	return func(s *Server) {
+		s.maxBodyBytes = maxBodyBytes
+		s.maxURLs = maxURLs
+	}
+}
+
+// WithQueueSize sets how many jobs may wait before submissions are rejected.
+func WithQueueSize(n int) Option {
+	return func(s *Server) {
+		s.queue = make(chan *job, n)
+	}
+}
+
+// WithJobTTL sets how long finished jobs stay available for polling.
+func WithJobTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.jobTTL = ttl
+	}
+}
+
+// WithHistory saves store after every completed job. It should be the store
+// the Processor records into.
+func WithHistory(store *history.Store) Option {
+	return func(s *Server) {
+		s.history = store
+	}
+}
+
+// WithFormatOptions applies opts to the formatter used for job results.
+func WithFormatOptions(opts ...output.Option) Option {
+	return func(s *Server) {
+		// Clipped so per-request appends never share the backing array
+		s.formatOpts = slices.Clip(opts)
+	}
+}
+
+// New creates a Server that reports articles scoring at least threshold
+// unless a job overrides it.
+func New(proc *processor.Processor, threshold int, opts ...Option) *Server {
+	s := &Server{
+		proc:         proc,
+		threshold:    threshold,
+		maxBodyBytes: 1 << 20,
+		maxURLs:      100,
+		jobTTL:       time.Hour,
+		queue:        make(chan *job, 16),
+		stopping:     make(chan struct{}),
+		done:         make(chan struct{}),
+		jobs:         make(map[string]*job),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Run processes queued jobs until Shutdown is called or ctx is cancelled.
+// Cancelling ctx also interrupts the running job. Jobs still queued when
+// Run returns are marked failed.
+func (s *Server) Run(ctx context.Context) {
+	defer close(s.done)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	s.mu.Lock()
+	s.cancelRun = cancel
+	s.mu.Unlock()
+
+	for {
+		// Shutdown takes priority over jobs that are already queued
+		select {
+		case <-s.stopping:
+			s.drain()
+			return
+		default:
+		}
+
+		select {
+		case <-ctx.Done():
+			s.drain()
+			return
+		case <-s.stopping:
+			s.drain()
+			return
+		case j := <-s.queue:
+			s.runJob(ctx, j)
+		}
+	}
+}
+
+// Shutdown stops accepting jobs, fails the queued ones and waits for the
+// running job to finish. If ctx ends first, the running job is interrupted
+// and Shutdown returns ctx.Err() once Run has returned. Run must have been
+// started.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.stopping)
+	}
+	s.mu.Unlock()
+	s.drain()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+	}
+
+	s.mu.Lock()
+	cancel := s.cancelRun
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	<-s.done
+	return ctx.Err()
+}
+
+// runJob processes a single job and records its results.
+func (s *Server) runJob(ctx context.Context, j *job) {
+	j.update(func() {
+		j.status = StatusRunning
+		j.started = time.Now()
+	})
+
+	// History records the articles this job reports, at its own threshold
+	results := s.proc.Process(processor.WithThreshold(ctx, j.threshold), j.jobs, func(completed, total int, result *processor.Result) {
+		j.update(func() {
+			j.completed = completed
+		})
+	})
+
+	if ctx.Err() != nil {
+		j.finish(StatusFailed, "interrupted", results)
+		return
+	}
+
+	if s.history != nil {
+		// History is best-effort; a failed save only repeats articles next time
+		_ = s.history.Save()
+	}
+	j.finish(StatusDone, "", results)
+}
+
+// drain fails every job left in the queue.
+func (s *Server) drain() {
+	for {
+		select {
+		case j := <-s.queue:
+			j.finish(StatusFailed, "server shutting down", nil)
+		default:
+			return
+		}
+	}
+}
+
+// submit registers and enqueues a job. It fails with errQueueFull when the
+// queue is full and errShuttingDown after Shutdown.
+func (s *Server) submit(jobs []processor.Job, threshold int) (*job, error) {
+	j := &job{
+		id:        newID(),
+		jobs:      jobs,
+		threshold: threshold,
+		created:   time.Now(),
+		status:    StatusQueued,
+		changed:   make(chan struct{}),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, errShuttingDown
+	}
+	s.pruneLocked()
+
+	select {
+	case s.queue <- j:
+	default:
+		return nil, errQueueFull
+	}
+	s.jobs[j.id] = j
+	return j, nil
+}
+
+// lookup returns the job with the given id.
+func (s *Server) lookup(id string) (*job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	return j, ok
+}
+
+// pruneLocked forgets finished jobs older than the TTL. s.mu must be held.
+func (s *Server) pruneLocked() {
+	for id, j := range s.jobs {
+		if finished := j.finishedAt(); !finished.IsZero() && time.Since(finished) > s.jobTTL {
+			delete(s.jobs, id)
+		}
+	}
+}
+
+// job is a batch of URLs submitted in one request.
+type job struct {
+	id        string
+	jobs      []processor.Job
+	threshold int
+	created   time.Time
+
+	mu        sync.Mutex
+	status    Status
+	completed int
+	started   time.Time
+	finished  time.Time
+	err       string
+	results   []processor.Result
+	changed   chan struct{} // closed and replaced on every update
+}
+
+// jobStatus is the JSON view of a job.
+type jobStatus struct {
+	ID         string    `json:"id"`
+	Status     Status    `json:"status"`
+	Total      int       `json:"total"`
+	Completed  int       `json:"completed"`
+	Threshold  int       `json:"threshold"`
+	Error      string    `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	StartedAt  time.Time `json:"started_at,omitzero"`
+	FinishedAt time.Time `json:"finished_at,omitzero"`
+}
+
+// update applies fn under the lock and wakes status watchers.
+func (j *job) update(fn func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	fn()
+	close(j.changed)
+	j.changed = make(chan struct{})
+}
+
+// finish marks the job as terminal with the given results.
+func (j *job) finish(status Status, errMsg string, results []processor.Result) {
+	j.update(func() {
+		j.status = status
+		j.err = errMsg
+		j.results = results
+		j.finished = time.Now()
+	})
+}
+
+// snapshot returns the current status and a channel closed on the next update.
+func (j *job) snapshot() (jobStatus, <-chan struct{}) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return jobStatus{
+		ID:         j.id,
+		Status:     j.status,
+		Total:      len(j.jobs),
+		Completed:  j.completed,
+		Threshold:  j.threshold,
+		Error:      j.err,
+		CreatedAt:  j.created,
+		StartedAt:  j.started,
+		FinishedAt: j.finished,
+	}, j.changed
+}
+
+// result returns the results once the job has finished successfully.
+func (j *job) result() ([]processor.Result, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.results, j.status == StatusDone
+}
+
+// finishedAt returns when the job finished, zero while it is pending.
+func (j *job) finishedAt() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	return j.finished
+}
+
+// terminal reports whether no further updates will happen.
+func (s Status) terminal() bool {
+	return s == StatusDone || s == StatusFailed
+}
+
+// newID returns a random job identifier.
+func newID() string {
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
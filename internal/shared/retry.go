@@ -0,0 +1,69 @@
+// Package shared holds small helpers used by several packages: retry
+// timing, Retry-After parsing and text truncation.
+package shared
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Backoff returns the jittered delay before the given retry (1-based),
+// doubling from base up to maxDelay. A server-provided Retry-After takes
+// precedence when it is longer.
+func Backoff(retry int, base, maxDelay, retryAfter time.Duration) time.Duration {
+	delay := base << (retry - 1)
+	if delay <= 0 || delay > maxDelay {
+		delay = maxDelay
+	}
+
+	// Equal jitter: keep half the delay, randomize the rest
+	if half := delay / 2; half > 0 {
+		delay = half + rand.N(half)
+	}
+
+	if retryAfter > delay {
+		return retryAfter
+	}
+	return delay
+}
+
+// ParseRetryAfter reads the Retry-After header in either seconds or HTTP-date form.
+func ParseRetryAfter(header http.Header) time.Duration {
+	if header == nil {
+		return 0
+	}
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds > 0 {
+		// Some services send fractional seconds
+		return time.Duration(seconds * float64(time.Second))
+	}
+
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// SleepContext waits for d and reports false if ctx was cancelled first.
+func SleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
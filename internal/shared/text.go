@@ -0,0 +1,11 @@
+package shared
+
+// Truncate shortens s to at most n runes, ending with "…" when cut.
+// A non-positive n leaves s unchanged.
+func Truncate(s string, n int) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,25 @@
+package shared
+
+import "testing"
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{s: "hello", n: 10, want: "hello"},
+		{s: "hello", n: 5, want: "hello"},
+		{s: "hello", n: 4, want: "hel…"},
+		{s: "こんにちは", n: 3, want: "こん…"},
+		{s: "hello", n: 1, want: "…"},
+		{s: "hello", n: 0, want: "hello"},
+		{s: "hello", n: -1, want: "hello"},
+	}
+
+	for _, tt := range tests {
+		if got := Truncate(tt.s, tt.n); got != tt.want {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.n, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+// Package urlnorm canonicalizes article URLs so the same page is recognized
+// across the cache and history.
+package urlnorm
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Normalize canonicalizes a URL for use as a lookup key: scheme and host
+// are lowercased, default ports, fragments and utm_* tracking parameters are
+// dropped, and the remaining query parameters are sorted.
+func Normalize(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "http" && u.Port() == "80") || (u.Scheme == "https" && u.Port() == "443") {
+		u.Host = u.Hostname()
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if u.Path == "" {
+		u.Path = "/"
+	}
+
+	query := u.Query()
+	for name := range query {
+		if strings.HasPrefix(strings.ToLower(name), "utm_") {
+			query.Del(name)
+		}
+	}
+	// url.Values.Encode sorts by key
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
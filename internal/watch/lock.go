@@ -0,0 +1,95 @@
+package watch
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// ErrLocked is returned when another live process holds the lock.
+var ErrLocked = errors.New("another instance is running")
+
+// Lock is an exclusive lock file holding the owner's PID.
+type Lock struct {
+	path string
+}
+
+// Acquire creates the lock file at path. A lock left behind by a process
+// that no longer exists is taken over.
+func Acquire(path string) (*Lock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+
+	// Write the PID first and link it into place, so other processes never
+	// see a lock file without an owner
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".lock-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lock file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := fmt.Fprintf(tmp, "%d\n", os.Getpid()); err != nil {
+		tmp.Close()
+		return nil, fmt.Errorf("failed to write lock file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write lock file: %w", err)
+	}
+
+	// Two attempts: the second runs after removing a stale lock
+	for range 2 {
+		err := os.Link(tmp.Name(), path)
+		if err == nil {
+			return &Lock{path: path}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		pid, alive := lockOwner(path)
+		if alive {
+			return nil, fmt.Errorf("%w (pid %d, lock %s)", ErrLocked, pid, path)
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to remove stale lock: %w", err)
+		}
+	}
+
+	return nil, fmt.Errorf("%w (lock %s)", ErrLocked, path)
+}
+
+// Release removes the lock file.
+func (l *Lock) Release() error {
+	if err := os.Remove(l.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to release lock: %w", err)
+	}
+	return nil
+}
+
+// lockOwner returns the PID recorded in the lock file and whether that
+// process is still running. Unreadable locks are treated as stale.
+func lockOwner(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return 0, false
+	}
+
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return pid, false
+	}
+	// Signal 0 checks for existence; EPERM means it exists under another user
+	err = proc.Signal(syscall.Signal(0))
+	return pid, err == nil || errors.Is(err, syscall.EPERM)
+}